@@ -1,36 +1,120 @@
 package go_annotation
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 type AnnotationParser interface {
 	Parse(comments []string) map[string]*Annotation
 }
 
-func getAnnotationParser(mode AnnotationMode) AnnotationParser {
-	switch mode {
-	case AnnotationModeMap:
-		return &MapAnnotationParser{}
-	default:
-		return &ArrayAnnotationParser{}
+// AnnotationChecker 注解检查器 注解解析器可选实现 用于报告格式错误的注解
+// comment: 去除注解前缀后的单条注解 如 annotation(name="test")
+type AnnotationChecker interface {
+	Check(comment string) error
+}
+
+// AnnotationParserFactory 注解解析器工厂 每组解析选项创建一个解析器
+type AnnotationParserFactory func() AnnotationParser
+
+var (
+	parserFactoriesMu sync.RWMutex
+	parserFactories   = make(map[AnnotationMode]AnnotationParserFactory)
+)
+
+func init() {
+	mustRegisterAnnotationParser(AnnotationModeArray, func() AnnotationParser { return &ArrayAnnotationParser{} })
+	mustRegisterAnnotationParser(AnnotationModeArrayNamed, func() AnnotationParser { return &ArrayAnnotationParser{NamedAttributes: true} })
+	mustRegisterAnnotationParser(AnnotationModeMap, func() AnnotationParser { return &MapAnnotationParser{} })
+	mustRegisterAnnotationParser(AnnotationModeSwag, func() AnnotationParser { return &SwagAnnotationParser{} })
+}
+
+// RegisterAnnotationParser 注册注解模式 注册后可通过Options.Mode、命令行及配置文件按名称使用
+// 模式名称为空或已被注册时返回错误
+func RegisterAnnotationParser(mode AnnotationMode, factory AnnotationParserFactory) error {
+	if mode == "" || factory == nil {
+		return fmt.Errorf("annotation mode and parser factory are required")
+	}
+	parserFactoriesMu.Lock()
+	defer parserFactoriesMu.Unlock()
+	if _, ok := parserFactories[mode]; ok {
+		return fmt.Errorf("annotation mode %s is already registered", mode)
+	}
+	parserFactories[mode] = factory
+	return nil
+}
+
+func mustRegisterAnnotationParser(mode AnnotationMode, factory AnnotationParserFactory) {
+	if err := RegisterAnnotationParser(mode, factory); err != nil {
+		panic(err)
+	}
+}
+
+// NewAnnotationParser 根据注解模式创建注解解析器 模式未注册时返回错误
+func NewAnnotationParser(mode AnnotationMode) (AnnotationParser, error) {
+	parserFactoriesMu.RLock()
+	factory, ok := parserFactories[mode]
+	parserFactoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown annotation mode: %s", mode)
+	}
+	return factory(), nil
+}
+
+// AnnotationModes 已注册的注解模式 按名称排序
+func AnnotationModes() []AnnotationMode {
+	parserFactoriesMu.RLock()
+	defer parserFactoriesMu.RUnlock()
+	modes := make([]AnnotationMode, 0, len(parserFactories))
+	for mode := range parserFactories {
+		modes = append(modes, mode)
 	}
+	sort.Slice(modes, func(i, j int) bool { return modes[i] < modes[j] })
+	return modes
 }
 
-type ArrayAnnotationParser struct{}
+// getAnnotationParser 根据注解模式创建注解解析器 模式未注册时返回错误及数组模式解析器 避免后续解析时出现空指针
+func getAnnotationParser(mode AnnotationMode) (AnnotationParser, error) {
+	parser, err := NewAnnotationParser(mode)
+	if err != nil {
+		return &ArrayAnnotationParser{}, err
+	}
+	return parser, nil
+}
+
+// ArrayAnnotationParser 数组注解解析器 按空白切分注解 属性名为其位置 如 0、1
+// 双引号或反引号内的空白不作为分隔符 整个参数为字符串时去除引号 双引号字符串支持转义
+type ArrayAnnotationParser struct {
+	// NamedAttributes 是否将 key=value 形式的参数同时作为命名属性 位置属性保持不变
+	// 如 @route /a method=GET 解析为 {"0": "/a", "1": "method=GET", "method": "GET"}
+	NamedAttributes bool
+}
 
 func (a *ArrayAnnotationParser) Parse(comments []string) map[string]*Annotation {
 	annotations := make(map[string]*Annotation)
 	for _, comment := range comments {
-		commentSlice := splitComment(comment)
-		if len(commentSlice) == 0 {
+		// 格式错误时使用已解析的部分 错误由Check报告
+		args, _ := splitComment(comment)
+		if len(args) == 0 {
 			continue
 		}
-		name := commentSlice[0]
+		name := args[0]
 		attribute := make(map[string]string)
-		for i := 1; i < len(commentSlice); i++ {
-			attribute[strconv.Itoa(i-1)] = commentSlice[i]
+		for i := 1; i < len(args); i++ {
+			attribute[strconv.Itoa(i-1)] = unquoteArg(args[i])
+		}
+		if a.NamedAttributes {
+			for _, arg := range args[1:] {
+				if key, value, ok := namedArg(arg); ok {
+					attribute[key] = value
+				}
+			}
 		}
 		if _, ok := annotations[name]; ok {
 			if len(attribute) > 0 {
@@ -47,46 +131,120 @@ func (a *ArrayAnnotationParser) Parse(comments []string) map[string]*Annotation
 	return annotations
 }
 
+// Check 检查注解格式 引号须闭合 双引号字符串的转义须有效
+func (a *ArrayAnnotationParser) Check(comment string) error {
+	args, err := splitComment(comment)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if arg[0] != '"' || closingQuote(arg, 0) != len(arg) {
+			continue
+		}
+		if _, err = strconv.Unquote(arg); err != nil {
+			return fmt.Errorf("invalid string %s: %s", arg, err)
+		}
+	}
+	return nil
+}
+
+// splitComment 按空白切分注解 半角及全角空白均为分隔符 引号内的空白除外
+// 参数保留原始文本 出错时返回已切分的部分及未闭合的参数
+func splitComment(comment string) ([]string, error) {
+	var args []string
+	start := -1
+	for pos := 0; pos < len(comment); {
+		r, size := utf8.DecodeRuneInString(comment[pos:])
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				args = append(args, comment[start:pos])
+				start = -1
+			}
+			pos += size
+			continue
+		}
+		if start < 0 {
+			start = pos
+		}
+		if r == '"' || r == '`' {
+			end := closingQuote(comment, pos)
+			if end < 0 {
+				return append(args, comment[start:]), fmt.Errorf("unterminated string %s", comment[pos:])
+			}
+			pos = end
+			continue
+		}
+		pos += size
+	}
+	if start >= 0 {
+		args = append(args, comment[start:])
+	}
+	return args, nil
+}
+
+// closingQuote 返回从start开始的字符串的结束位置 未闭合时返回-1
+func closingQuote(s string, start int) int {
+	quote := s[start]
+	for pos := start + 1; pos < len(s); pos++ {
+		switch {
+		case s[pos] == '\\' && quote == '"':
+			pos++
+		case s[pos] == quote:
+			return pos + 1
+		}
+	}
+	return -1
+}
+
+// unquoteArg 参数整体为字符串时去除引号及转义 否则返回原始文本
+func unquoteArg(arg string) string {
+	if arg == "" || (arg[0] != '"' && arg[0] != '`') || closingQuote(arg, 0) != len(arg) {
+		return arg
+	}
+	if s, err := strconv.Unquote(arg); err == nil {
+		return s
+	}
+	return arg
+}
+
+// namedArg 解析 key=value 形式的参数 key须为标识符 引号内的等号不作为分隔符
+func namedArg(arg string) (key string, value string, ok bool) {
+	i := strings.IndexAny(arg, "=\"`")
+	if i <= 0 || arg[i] != '=' {
+		return "", "", false
+	}
+	key = arg[:i]
+	for j, r := range key {
+		if !(unicode.IsLetter(r) || r == '_' || (j > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'))) {
+			return "", "", false
+		}
+	}
+	return key, unquoteArg(arg[i+1:]), true
+}
+
 type MapAnnotationParser struct{}
 
 func (a *MapAnnotationParser) Parse(comments []string) map[string]*Annotation {
 	annotations := make(map[string]*Annotation)
 	for _, comment := range comments {
-		if strings.Contains(comment, "(") {
-			// get annotation name
-			name := comment[:strings.Index(comment, "(")]
-			// get attribute
-			attributeStr := comment[strings.Index(comment, "(")+1 : strings.LastIndex(comment, ")")]
-			attributeSlice := strings.Split(attributeStr, ",")
-			attribute := make(map[string]string)
-			for _, item := range attributeSlice {
-				// get attribute name and value
-				itemSlice := strings.Split(item, "=")
-				if len(itemSlice) != 2 {
-					continue
-				}
-				attributeName := strings.TrimSpace(itemSlice[0])
-				attributeValue := strings.TrimSpace(itemSlice[1])
-				if strings.HasPrefix(attributeValue, "\"") && strings.HasSuffix(attributeValue, "\"") {
-					attributeValue = attributeValue[1 : len(attributeValue)-1]
-				}
-				attribute[attributeName] = attributeValue
-			}
-			if _, ok := annotations[name]; ok {
-				if len(attribute) > 0 {
-					annotations[name].Attributes = append(annotations[name].Attributes, attribute)
-				}
-			} else {
-				annotation := &Annotation{Name: name, Attributes: []map[string]string{}}
-				if len(attribute) > 0 {
-					annotation.Attributes = append(annotation.Attributes, attribute)
-				}
-				annotations[name] = annotation
-			}
-		} else {
-			annotation := &Annotation{Name: comment, Attributes: []map[string]string{}}
-			annotations[comment] = annotation
+		// 格式错误时使用已解析的部分 错误由Check报告
+		item, _ := parseMapAnnotation(comment)
+		if item == nil {
+			continue
 		}
+		annotation, ok := annotations[item.Name]
+		if !ok {
+			annotations[item.Name] = item
+			continue
+		}
+		annotation.Attributes = append(annotation.Attributes, item.Attributes...)
+		annotation.Values = append(annotation.Values, item.Values...)
 	}
 	return annotations
 }
+
+// Check 检查注解格式 name 或 name(key=value, ...)
+func (a *MapAnnotationParser) Check(comment string) error {
+	_, err := parseMapAnnotation(comment)
+	return err
+}
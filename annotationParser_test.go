@@ -0,0 +1,303 @@
+package go_annotation
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArrayAnnotationParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  *ArrayAnnotationParser
+		comment string
+		want    *Annotation
+		wantErr bool
+	}{
+		{
+			name:    "半角及全角空白",
+			parser:  &ArrayAnnotationParser{},
+			comment: "desc a　b\tc",
+			want:    &Annotation{Name: "desc", Attributes: []map[string]string{{"0": "a", "1": "b", "2": "c"}}},
+		},
+		{
+			name:    "引号内的空白",
+			parser:  &ArrayAnnotationParser{},
+			comment: "desc \"hello world\" `C:\\my dir` \"say \\\"hi\\\"\"",
+			want:    &Annotation{Name: "desc", Attributes: []map[string]string{{"0": "hello world", "1": `C:\my dir`, "2": `say "hi"`}}},
+		},
+		{
+			name:    "参数中的引号保留原文",
+			parser:  &ArrayAnnotationParser{},
+			comment: `table(name="user name") key="a b"`,
+			want:    &Annotation{Name: `table(name="user name")`, Attributes: []map[string]string{{"0": `key="a b"`}}},
+		},
+		{
+			name:    "默认不解析命名属性",
+			parser:  &ArrayAnnotationParser{},
+			comment: `route /a method=GET`,
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{{"0": "/a", "1": "method=GET"}}},
+		},
+		{
+			name:    "命名属性",
+			parser:  &ArrayAnnotationParser{NamedAttributes: true},
+			comment: `route /a method=GET desc="a=b c" 1=x ="y"`,
+			want: &Annotation{Name: "route", Attributes: []map[string]string{{
+				"0": "/a", "1": "method=GET", "2": `desc="a=b c"`, "3": "1=x", "4": `="y"`,
+				"method": "GET", "desc": "a=b c",
+			}}},
+		},
+		{
+			name:    "未闭合的字符串",
+			parser:  &ArrayAnnotationParser{},
+			comment: `desc a "hello world`,
+			want:    &Annotation{Name: "desc", Attributes: []map[string]string{{"0": "a", "1": `"hello world`}}},
+			wantErr: true,
+		},
+		{
+			name:    "无效的转义",
+			parser:  &ArrayAnnotationParser{},
+			comment: `desc "a\qb"`,
+			want:    &Annotation{Name: "desc", Attributes: []map[string]string{{"0": `"a\qb"`}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parser.Check(tt.comment); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := tt.parser.Parse([]string{tt.comment})[tt.want.Name]
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapAnnotationParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    *Annotation
+		wantErr bool
+	}{
+		{
+			name:    "引号内的逗号和等号",
+			comment: `route(path="/a,b", q="x=y")`,
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{{"path": "/a,b", "q": "x=y"}}},
+		},
+		{
+			name:    "引号内的括号",
+			comment: `route(path="/a(b)", desc=")")`,
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{{"path": "/a(b)", "desc": ")"}}},
+		},
+		{
+			name:    "反引号字符串",
+			comment: "route(pattern=`^\\d+,\"$`)",
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{{"pattern": `^\d+,"$`}}},
+		},
+		{
+			name:    "名称与括号间的空白",
+			comment: `route  ( path = "/a" , )`,
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{{"path": "/a"}}},
+		},
+		{
+			name:    "未加引号的多个单词",
+			comment: `doc(desc=hello world, name= a  b )`,
+			want:    &Annotation{Name: "doc", Attributes: []map[string]string{{"desc": "hello world", "name": "a  b"}}},
+		},
+		{
+			name:    "多个单词后的字符串",
+			comment: `doc(desc=hello "world")`,
+			want:    &Annotation{Name: "doc", Attributes: []map[string]string{{"desc": "hello"}}},
+			wantErr: true,
+		},
+		{
+			name:    "无属性",
+			comment: `route()`,
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{}},
+		},
+		{
+			name:    "缺少右括号",
+			comment: `route(path="/a"`,
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{{"path": "/a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "缺少等号",
+			comment: `route(path="/a", method)`,
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{{"path": "/a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "括号后多余内容",
+			comment: `route(path="/a") extra`,
+			want:    &Annotation{Name: "route", Attributes: []map[string]string{{"path": "/a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "名称为空",
+			comment: `(path="/a")`,
+			wantErr: true,
+		},
+	}
+	parser := &MapAnnotationParser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := parser.Check(tt.comment); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got, _ := parseMapAnnotation(tt.comment)
+			if got != nil {
+				got.Values = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMapAnnotation() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwagAnnotationParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    *Annotation
+		wantErr bool
+	}{
+		{
+			name:    "引号内的描述",
+			comment: `Param id path int true "用户 ID"`,
+			want:    &Annotation{Name: "Param", Attributes: []map[string]string{{"0": "id", "1": "path", "2": "int", "3": "true", "4": "用户 ID"}}},
+		},
+		{
+			name:    "花括号类型",
+			comment: `Success 200 {object} model.Response{data=[]model.User, msg=string} "成功"`,
+			want:    &Annotation{Name: "Success", Attributes: []map[string]string{{"0": "200", "1": "{object}", "2": "model.Response{data=[]model.User, msg=string}", "3": "成功"}}},
+		},
+		{
+			name:    "方括号方法",
+			comment: `Router /users/{id} [get]`,
+			want:    &Annotation{Name: "Router", Attributes: []map[string]string{{"0": "/users/{id}", "1": "[get]"}}},
+		},
+		{
+			name:    "圆括号属性",
+			comment: `Param status query string false "状态" Enums(A, B) default("A B")`,
+			want:    &Annotation{Name: "Param", Attributes: []map[string]string{{"0": "status", "1": "query", "2": "string", "3": "false", "4": "状态", "5": "Enums(A, B)", "6": `default("A B")`}}},
+		},
+		{
+			name:    "无属性",
+			comment: `Deprecated`,
+			want:    &Annotation{Name: "Deprecated", Attributes: []map[string]string{}},
+		},
+		{
+			name:    "未闭合的字符串",
+			comment: `Summary "获取用户`,
+			want:    &Annotation{Name: "Summary", Attributes: []map[string]string{}},
+			wantErr: true,
+		},
+		{
+			name:    "未闭合的括号",
+			comment: `Success 200 {object model.User`,
+			want:    &Annotation{Name: "Success", Attributes: []map[string]string{{"0": "200"}}},
+			wantErr: true,
+		},
+	}
+	parser := &SwagAnnotationParser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := parser.Check(tt.comment); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := parser.Parse([]string{tt.comment})[tt.want.Name]
+			if got != nil {
+				got.Values = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzMapAnnotationParser(f *testing.F) {
+	seeds := []string{
+		`route(path="/a,b", q="x=y")`,
+		"route(pattern=`a\\b`, list=[1, 2.5, true, 10s,], h=@Header(name=\"x\"))",
+		`route(path="/a"`,
+		`route(path="\"`,
+		`route(a=[[[`,
+		`a=)(`,
+		"",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	parser := &MapAnnotationParser{}
+	f.Fuzz(func(t *testing.T, comment string) {
+		err := parser.Check(comment)
+		annotations := parser.Parse([]string{comment})
+		if err == nil && len(annotations) != 1 {
+			t.Errorf("Parse(%q) got %d annotations, want 1", comment, len(annotations))
+		}
+		for name, annotation := range annotations {
+			if name == "" || len(annotation.Attributes) != len(annotation.Values) {
+				t.Errorf("Parse(%q) = %+v, want named annotation with matching values", comment, annotation)
+			}
+		}
+		if _, err := json.Marshal(annotations); err != nil {
+			t.Errorf("json.Marshal(Parse(%q)) error = %v", comment, err)
+		}
+	})
+}
+
+// keyValueParser 自定义注解解析器 解析 name key:value ...
+type keyValueParser struct{}
+
+func (p *keyValueParser) Parse(comments []string) map[string]*Annotation {
+	annotations := make(map[string]*Annotation)
+	for _, comment := range comments {
+		fields := strings.Fields(comment)
+		if len(fields) == 0 {
+			continue
+		}
+		attribute := make(map[string]string)
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, ":")
+			attribute[key] = value
+		}
+		annotations[fields[0]] = &Annotation{Name: fields[0], Attributes: []map[string]string{attribute}}
+	}
+	return annotations
+}
+
+// keyValueMode 自定义注解模式 注册无法撤销 在init中只注册一次
+const keyValueMode AnnotationMode = "keyvalue"
+
+func init() {
+	mustRegisterAnnotationParser(keyValueMode, func() AnnotationParser { return &keyValueParser{} })
+}
+
+func TestRegisterAnnotationParser(t *testing.T) {
+	if err := RegisterAnnotationParser(keyValueMode, func() AnnotationParser { return &keyValueParser{} }); err == nil {
+		t.Errorf("RegisterAnnotationParser() registered custom mode twice")
+	}
+	if err := RegisterAnnotationParser(AnnotationModeMap, func() AnnotationParser { return &keyValueParser{} }); err == nil {
+		t.Errorf("RegisterAnnotationParser() registered builtin mode twice")
+	}
+	if _, err := NewAnnotationParser("unknown"); err == nil {
+		t.Errorf("NewAnnotationParser() error = nil, want unknown mode error")
+	}
+	if modes := AnnotationModes(); !reflect.DeepEqual(modes, []AnnotationMode{AnnotationModeArray, AnnotationModeArrayNamed, keyValueMode, AnnotationModeMap, AnnotationModeSwag}) {
+		t.Errorf("AnnotationModes() = %v", modes)
+	}
+	fileDesc, err := GetFileDesc("test/data/arraymode/arraymode_single_struct.go", keyValueMode)
+	if err != nil {
+		t.Fatalf("GetFileDesc() error = %v", err)
+	}
+	if _, ok := fileDesc.Structs[0].Annotations["annotation"]; !ok {
+		t.Errorf("GetFileDesc() annotations = %v, want parsed by custom parser", fileDesc.Structs[0].Annotations)
+	}
+}
@@ -0,0 +1,314 @@
+package go_annotation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int // 词法单元类型
+
+const (
+	tokenEOF      tokenKind = iota // 结束
+	tokenIllegal                   // 非法内容 如未闭合的字符串
+	tokenLiteral                   // 未加引号的文本 如名称、数值、标识符
+	tokenString                    // 字符串 "..." 或 `...`
+	tokenAt                        // @
+	tokenLParen                    // (
+	tokenRParen                    // )
+	tokenLBracket                  // [
+	tokenRBracket                  // ]
+	tokenComma                     // ,
+	tokenAssign                    // =
+)
+
+// delimiters 分隔符 literal不能包含这些字符
+const delimiters = "@()[],=\"`"
+
+var punctuations = map[byte]tokenKind{
+	'@': tokenAt,
+	'(': tokenLParen,
+	')': tokenRParen,
+	'[': tokenLBracket,
+	']': tokenRBracket,
+	',': tokenComma,
+	'=': tokenAssign,
+}
+
+type annotationToken struct {
+	kind tokenKind
+	text string // 原始文本
+	pos  int    // 在源文本中的偏移
+	err  string // 非法内容的错误信息
+}
+
+func (t annotationToken) String() string {
+	if t.kind == tokenEOF {
+		return "end of annotation"
+	}
+	return strconv.Quote(t.text)
+}
+
+// annotationLexer 注解词法分析器
+type annotationLexer struct {
+	src string
+	pos int
+}
+
+func (l *annotationLexer) next() annotationToken {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return annotationToken{kind: tokenEOF, pos: start}
+	}
+	c := l.src[l.pos]
+	if c == '"' || c == '`' {
+		return l.scanString(c)
+	}
+	if kind, ok := punctuations[c]; ok {
+		l.pos++
+		return annotationToken{kind: kind, text: l.src[start:l.pos], pos: start}
+	}
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if unicode.IsSpace(r) || strings.ContainsRune(delimiters, r) {
+			break
+		}
+		l.pos += size
+	}
+	return annotationToken{kind: tokenLiteral, text: l.src[start:l.pos], pos: start}
+}
+
+// scanString 扫描字符串 双引号字符串支持转义 反引号字符串不支持
+func (l *annotationLexer) scanString(quote byte) annotationToken {
+	start := l.pos
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		c := l.src[l.pos]
+		if c == '\\' && quote == '"' {
+			l.pos++
+			continue
+		}
+		if c == quote {
+			l.pos++
+			return annotationToken{kind: tokenString, text: l.src[start:l.pos], pos: start}
+		}
+	}
+	l.pos = len(l.src)
+	return annotationToken{kind: tokenIllegal, text: l.src[start:], pos: start, err: "unterminated string"}
+}
+
+// annotationSyntaxParser 注解语法分析器
+// annotation = name [ "(" [ attribute { "," attribute } [ "," ] ] ")" ]
+// attribute  = name "=" ( value | words )
+// words      = literal literal { literal } 未加引号的多个单词 到下一个 , 或 ) 为止 视为一个字符串
+// value      = string | literal | list | "@" annotation
+// list       = "[" [ value { "," value } [ "," ] ] "]"
+type annotationSyntaxParser struct {
+	lexer annotationLexer
+	tok   annotationToken
+	end   int // 上一个词法单元的结束位置
+}
+
+func newAnnotationSyntaxParser(src string) *annotationSyntaxParser {
+	p := &annotationSyntaxParser{lexer: annotationLexer{src: src}}
+	p.next()
+	return p
+}
+
+func (p *annotationSyntaxParser) next() {
+	p.end = p.tok.pos + len(p.tok.text)
+	p.tok = p.lexer.next()
+}
+
+func (p *annotationSyntaxParser) unexpected(context string) error {
+	if p.tok.kind == tokenIllegal {
+		return fmt.Errorf("%s", p.tok.err)
+	}
+	return fmt.Errorf("unexpected %s %s", p.tok, context)
+}
+
+// parseMapAnnotation 解析map模式的单条注解
+// 出错时返回已解析的部分 名称无法解析时返回nil
+func parseMapAnnotation(comment string) (*Annotation, error) {
+	p := newAnnotationSyntaxParser(comment)
+	annotation, err := p.parseAnnotation()
+	if err != nil {
+		return annotation, err
+	}
+	if p.tok.kind != tokenEOF {
+		return annotation, p.unexpected("after annotation")
+	}
+	return annotation, nil
+}
+
+// parseAnnotationValue 解析单个注解属性值
+func parseAnnotationValue(raw string) (*AnnotationValue, error) {
+	p := newAnnotationSyntaxParser(raw)
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, p.unexpected("after value")
+	}
+	return value, nil
+}
+
+func (p *annotationSyntaxParser) parseAnnotation() (*Annotation, error) {
+	if p.tok.kind != tokenLiteral {
+		if p.tok.kind == tokenEOF || p.tok.kind == tokenLParen {
+			return nil, fmt.Errorf("annotation name is empty")
+		}
+		return nil, p.unexpected("as annotation name")
+	}
+	annotation := &Annotation{Name: p.tok.text, Attributes: []map[string]string{}}
+	p.next()
+	if p.tok.kind != tokenLParen {
+		return annotation, nil
+	}
+	p.next()
+	attribute := make(map[string]string)
+	values := make(map[string]*AnnotationValue)
+	// 出错时保留已解析的属性
+	defer func() {
+		if len(attribute) > 0 {
+			annotation.Attributes = append(annotation.Attributes, attribute)
+			annotation.Values = append(annotation.Values, values)
+		}
+	}()
+	for p.tok.kind != tokenRParen {
+		if p.tok.kind != tokenLiteral {
+			if p.tok.kind == tokenEOF {
+				return annotation, fmt.Errorf("missing ')'")
+			}
+			return annotation, p.unexpected("in " + annotation.Name + ", expected attribute name")
+		}
+		key := p.tok.text
+		p.next()
+		if p.tok.kind != tokenAssign {
+			return annotation, fmt.Errorf("invalid attribute %q, expected name=value", key)
+		}
+		p.next()
+		value, err := p.parseAttributeValue()
+		if err != nil {
+			return annotation, fmt.Errorf("invalid value of attribute %s: %s", key, err)
+		}
+		attribute[key] = value.text()
+		values[key] = value
+		if p.tok.kind == tokenComma {
+			p.next()
+		} else if p.tok.kind != tokenRParen {
+			if p.tok.kind == tokenEOF {
+				return annotation, fmt.Errorf("missing ')'")
+			}
+			return annotation, p.unexpected("after attribute " + key)
+		}
+	}
+	p.next()
+	return annotation, nil
+}
+
+// parseAttributeValue 解析属性值 如 desc=hello world 未加引号的多个单词合并为一个字符串
+func (p *annotationSyntaxParser) parseAttributeValue() (*AnnotationValue, error) {
+	if p.tok.kind != tokenLiteral {
+		return p.parseValue()
+	}
+	start := p.tok.pos
+	value, err := p.parseValue()
+	if err != nil || p.tok.kind != tokenLiteral {
+		return value, err
+	}
+	for p.tok.kind == tokenLiteral {
+		p.next()
+	}
+	words := p.lexer.src[start:p.end]
+	return &AnnotationValue{Kind: AnnotationValueString, Raw: words, String: words}, nil
+}
+
+func (p *annotationSyntaxParser) parseValue() (*AnnotationValue, error) {
+	start := p.tok.pos
+	var value *AnnotationValue
+	switch p.tok.kind {
+	case tokenString:
+		s, err := strconv.Unquote(p.tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s: %s", p.tok.text, err)
+		}
+		value = &AnnotationValue{Kind: AnnotationValueString, String: s}
+		p.next()
+	case tokenLiteral:
+		value = literalValue(p.tok.text)
+		p.next()
+	case tokenLBracket:
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		value = list
+	case tokenAt:
+		p.next()
+		annotation, err := p.parseAnnotation()
+		if err != nil {
+			return nil, err
+		}
+		value = &AnnotationValue{Kind: AnnotationValueAnnotation, Annotation: annotation}
+	case tokenEOF:
+		return nil, fmt.Errorf("missing value")
+	default:
+		return nil, p.unexpected("as value")
+	}
+	value.Raw = p.lexer.src[start:p.end]
+	return value, nil
+}
+
+func (p *annotationSyntaxParser) parseList() (*AnnotationValue, error) {
+	value := &AnnotationValue{Kind: AnnotationValueList, List: make([]*AnnotationValue, 0)}
+	p.next() // [
+	for p.tok.kind != tokenRBracket {
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		value.List = append(value.List, item)
+		if p.tok.kind == tokenComma {
+			p.next()
+		} else if p.tok.kind != tokenRBracket {
+			if p.tok.kind == tokenEOF {
+				return nil, fmt.Errorf("missing ']'")
+			}
+			return nil, p.unexpected("in list")
+		}
+	}
+	p.next()
+	return value, nil
+}
+
+// literalValue 解析未加引号的值 依次尝试布尔值、整数、浮点数、时间间隔 均不是时视为标识符
+func literalValue(literal string) *AnnotationValue {
+	if literal == "true" || literal == "false" {
+		return &AnnotationValue{Kind: AnnotationValueBool, Bool: literal == "true"}
+	}
+	// 仅数字开头的值才可能是数值 避免将 Inf、NaN 等标识符解析为浮点数
+	if digits := strings.TrimLeft(literal, "+-"); digits == "" || !strings.ContainsRune("0123456789.", rune(digits[0])) {
+		return &AnnotationValue{Kind: AnnotationValueIdent, String: literal}
+	}
+	if i, err := strconv.ParseInt(literal, 0, 64); err == nil {
+		return &AnnotationValue{Kind: AnnotationValueInt, Int: i}
+	}
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		return &AnnotationValue{Kind: AnnotationValueFloat, Float: f}
+	}
+	if d, err := time.ParseDuration(literal); err == nil {
+		return &AnnotationValue{Kind: AnnotationValueDuration, Duration: d}
+	}
+	return &AnnotationValue{Kind: AnnotationValueIdent, String: literal}
+}
@@ -0,0 +1,41 @@
+package go_annotation
+
+// Interface 返回属性值对应的go值
+// string、int64、float64、bool、time.Duration、[]interface{} 或 *Annotation
+func (v *AnnotationValue) Interface() interface{} {
+	if v == nil {
+		return nil
+	}
+	switch v.Kind {
+	case AnnotationValueString, AnnotationValueIdent:
+		return v.String
+	case AnnotationValueInt:
+		return v.Int
+	case AnnotationValueFloat:
+		return v.Float
+	case AnnotationValueBool:
+		return v.Bool
+	case AnnotationValueDuration:
+		return v.Duration
+	case AnnotationValueList:
+		list := make([]interface{}, 0, len(v.List))
+		for _, item := range v.List {
+			list = append(list, item.Interface())
+		}
+		return list
+	case AnnotationValueAnnotation:
+		return v.Annotation
+	default:
+		return v.Raw
+	}
+}
+
+// text 属性值的字符串形式 用于兼容Attributes 字符串返回去除引号及转义后的值 其余返回原始文本
+func (v *AnnotationValue) text() string {
+	switch v.Kind {
+	case AnnotationValueString, AnnotationValueIdent:
+		return v.String
+	default:
+		return v.Raw
+	}
+}
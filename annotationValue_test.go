@@ -0,0 +1,40 @@
+package go_annotation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAnnotationValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "字符串", raw: `"a\"b"`, want: `a"b`},
+		{name: "原始字符串", raw: "`a\\b`", want: `a\b`},
+		{name: "负整数", raw: "-1", want: int64(-1)},
+		{name: "浮点数", raw: "1e3", want: float64(1000)},
+		{name: "时间间隔", raw: "500ms", want: 500 * time.Millisecond},
+		{name: "标识符", raw: "NaN", want: "NaN"},
+		{name: "列表", raw: `[1, "a", [true],]`, want: []interface{}{int64(1), "a", []interface{}{true}}},
+		{name: "未闭合的字符串", raw: `"a`, wantErr: true},
+		{name: "未闭合的列表", raw: `["a"`, wantErr: true},
+		{name: "未闭合的注解", raw: `@Header(name="x"`, wantErr: true},
+		{name: "多余内容", raw: `"a" "b"`, wantErr: true},
+		{name: "空值", raw: ` `, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAnnotationValue(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAnnotationValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Interface(), tt.want) {
+				t.Errorf("parseAnnotationValue() = %#v, want %#v", got.Interface(), tt.want)
+			}
+		})
+	}
+}
@@ -2,13 +2,21 @@ package go_annotation
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
+// update 使用 go test -run TestAnnotation -update 重新生成test/data下的json文件
+var update = flag.Bool("update", false, "update golden json files")
+
 func getInstanceFromJsonFile(fileName string) *FileDesc {
 	jsonFile, err := os.Open(fileName)
 	if err != nil {
@@ -30,6 +38,7 @@ func TestAnnotation(t *testing.T) {
 		name       string
 		fileName   string
 		mode       AnnotationMode
+		options    *Options // 为nil时仅设置mode
 		wantResult *FileDesc
 		wantErr    bool
 	}{
@@ -75,17 +84,123 @@ func TestAnnotation(t *testing.T) {
 			wantResult: getInstanceFromJsonFile("test/data/mapmode/mapmode_mult.json"),
 			wantErr:    false,
 		},
+		{
+			name:       "结构体字段测试",
+			fileName:   "test/data/fields/fields.go",
+			mode:       AnnotationModeMap,
+			wantResult: getInstanceFromJsonFile("test/data/fields/fields.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "包级函数测试",
+			fileName:   "test/data/funcs/funcs.go",
+			mode:       AnnotationModeArray,
+			wantResult: getInstanceFromJsonFile("test/data/funcs/funcs.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "map模式属性值测试",
+			fileName:   "test/data/values/values.go",
+			mode:       AnnotationModeMap,
+			wantResult: getInstanceFromJsonFile("test/data/values/values.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "map模式多行注解测试",
+			fileName:   "test/data/multiline/multiline.go",
+			mode:       AnnotationModeMap,
+			wantResult: getInstanceFromJsonFile("test/data/multiline/multiline.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "泛型测试",
+			fileName:   "test/data/generics/generics.go",
+			mode:       AnnotationModeArray,
+			wantResult: getInstanceFromJsonFile("test/data/generics/generics.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "参数测试",
+			fileName:   "test/data/params/params.go",
+			mode:       AnnotationModeArray,
+			wantResult: getInstanceFromJsonFile("test/data/params/params.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "嵌入类型测试",
+			fileName:   "test/data/embeds/embeds.go",
+			mode:       AnnotationModeMap,
+			wantResult: getInstanceFromJsonFile("test/data/embeds/embeds.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "具名类型及常量测试",
+			fileName:   "test/data/enums/enums.go",
+			mode:       AnnotationModeMap,
+			wantResult: getInstanceFromJsonFile("test/data/enums/enums.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "包注释测试",
+			fileName:   "test/data/pkgdoc/doc.go",
+			mode:       AnnotationModeMap,
+			wantResult: getInstanceFromJsonFile("test/data/pkgdoc/doc.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "文件级注解测试",
+			fileName:   "test/data/pkgdoc/pkgdoc.go",
+			mode:       AnnotationModeMap,
+			wantResult: getInstanceFromJsonFile("test/data/pkgdoc/pkgdoc.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "参数注解测试",
+			fileName:   "test/data/paramannotations/paramannotations.go",
+			mode:       AnnotationModeMap,
+			options:    &Options{Mode: AnnotationModeMap, ParamAnnotation: ParamAnnotation},
+			wantResult: getInstanceFromJsonFile("test/data/paramannotations/paramannotations.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "swag模式测试",
+			fileName:   "test/data/swag/swag.go",
+			mode:       AnnotationModeSwag,
+			wantResult: getInstanceFromJsonFile("test/data/swag/swag.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "数组模式块注释测试",
+			fileName:   "test/data/multiline/multiline_array.go",
+			mode:       AnnotationModeArray,
+			wantResult: getInstanceFromJsonFile("test/data/multiline/multiline_array.json"),
+			wantErr:    false,
+		},
+		{
+			name:       "跨文件方法测试",
+			fileName:   "test/data/receivers/receivers.go",
+			mode:       AnnotationModeArray,
+			wantResult: getInstanceFromJsonFile("test/data/receivers/receivers.json"),
+			wantErr:    false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			currentAnnotationMode = tt.mode
-			fileParser := GetFileParser(tt.fileName)
+			options := tt.options
+			if options == nil {
+				options = &Options{Mode: tt.mode}
+			}
+			fileParser := NewFileParser(tt.fileName, options)
 			fileDesc, err := fileParser.Parse()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if *update {
+				writeJsonFile(t, strings.TrimSuffix(tt.fileName, ".go")+".json", fileDesc)
+				return
+			}
 			if !deepCompare(fileDesc, tt.wantResult, "fileDesc") {
 				t.Errorf("Parse() gotResult = %v, want %v", fileDesc, tt.wantResult)
 			}
@@ -94,6 +209,217 @@ func TestAnnotation(t *testing.T) {
 
 }
 
+func writeJsonFile(t *testing.T, fileName string, fileDesc *FileDesc) {
+	data, err := json.MarshalIndent(fileDesc, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(fileName, append(data, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParserConcurrent(t *testing.T) {
+	tests := []struct {
+		fileName   string
+		mode       AnnotationMode
+		wantResult *FileDesc
+	}{
+		{
+			fileName:   "test/data/arraymode/arraymode_mult.go",
+			mode:       AnnotationModeArray,
+			wantResult: getInstanceFromJsonFile("test/data/arraymode/arraymode_mult.json"),
+		},
+		{
+			fileName:   "test/data/mapmode/mapmode_mult.go",
+			mode:       AnnotationModeMap,
+			wantResult: getInstanceFromJsonFile("test/data/mapmode/mapmode_mult.json"),
+		},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, tt := range tests {
+			wg.Add(1)
+			go func(fileName string, mode AnnotationMode, wantResult *FileDesc) {
+				defer wg.Done()
+				fileDesc, err := NewParser(&Options{Mode: mode}).GetFileDesc(fileName)
+				if err != nil {
+					t.Errorf("GetFileDesc() error = %v", err)
+					return
+				}
+				if !deepCompare(fileDesc, wantResult, "fileDesc") {
+					t.Errorf("GetFileDesc() mode %s gotResult = %v, want %v", mode, fileDesc, wantResult)
+				}
+			}(tt.fileName, tt.mode, tt.wantResult)
+		}
+	}
+	wg.Wait()
+}
+
+func TestParserOptions(t *testing.T) {
+	options := &Options{
+		Mode: AnnotationModeMap,
+		FileFilter: func(fileName string) bool {
+			return strings.HasSuffix(fileName, "_mult.go")
+		},
+		TypeFilter: func(typeName string) bool {
+			return typeName == "StructTwo"
+		},
+	}
+	filesDesc, err := NewParser(options).GetFilesDescList("test/data/mapmode")
+	if err != nil {
+		t.Fatalf("GetFilesDescList() error = %v", err)
+	}
+	if len(filesDesc) != 1 || filesDesc[0].FileName != "mapmode_mult.go" {
+		t.Fatalf("GetFilesDescList() gotResult = %v, want only mapmode_mult.go", filesDesc)
+	}
+	if len(filesDesc[0].Structs) != 1 || len(filesDesc[0].Interfaces) != 0 {
+		t.Errorf("GetFilesDescList() got %d structs and %d interfaces, want 1 struct", len(filesDesc[0].Structs), len(filesDesc[0].Interfaces))
+	}
+	if _, ok := filesDesc[0].Structs[0].Annotations["annotation"]; !ok {
+		t.Errorf("GetFilesDescList() struct annotations = %v, want map mode annotation", filesDesc[0].Structs[0].Annotations)
+	}
+	if options.AnnotationPrefix != "" || options.AnnotationParser != nil {
+		t.Errorf("NewParser() modified the caller's options")
+	}
+}
+
+func TestUnknownMode(t *testing.T) {
+	parser := NewParser(&Options{Mode: "mpa"})
+	if fileDesc, err := parser.GetFileDesc("test/data/mapmode/mapmode_mult.go"); err == nil || fileDesc != nil {
+		t.Errorf("GetFileDesc() = %v, %v, want unknown mode error", fileDesc, err)
+	}
+	if _, err := parser.GetFilesDescList("test/data/mapmode"); err == nil {
+		t.Errorf("GetFilesDescList() error = nil, want unknown mode error")
+	}
+	if _, err := parser.GetPackagesDescList("./test/data/mapmode"); err == nil {
+		t.Errorf("GetPackagesDescList() error = nil, want unknown mode error")
+	}
+}
+
+func TestGetFilesDescListMissingDir(t *testing.T) {
+	filesDesc, err := GetFilesDescList("test/data/nonexistent", AnnotationModeArray)
+	if !os.IsNotExist(err) || filesDesc != nil {
+		t.Errorf("GetFilesDescList() = %v, %v, want not exist error", filesDesc, err)
+	}
+}
+
+func TestGetPackagesDescList(t *testing.T) {
+	wantFiles := map[string]*FileDesc{
+		"arraymode_mult.go":             getInstanceFromJsonFile("test/data/arraymode/arraymode_mult.json"),
+		"arraymode_single_interface.go": getInstanceFromJsonFile("test/data/arraymode/arraymode_single_interface.json"),
+		"arraymode_single_struct.go":    getInstanceFromJsonFile("test/data/arraymode/arraymode_single_struct.json"),
+	}
+	pkgs, err := GetPackagesDescList(AnnotationModeArray, "./test/data/arraymode/...")
+	if err != nil {
+		t.Fatalf("GetPackagesDescList() error = %v", err)
+	}
+	var pkg *PackageDesc
+	for _, item := range pkgs {
+		if item.PkgPath == "github.com/celt237/go-annotation/test/data/arraymode" {
+			pkg = item
+		}
+	}
+	if pkg == nil {
+		t.Fatalf("GetPackagesDescList() arraymode package not found")
+	}
+	if pkg.Name != "arraymode" || pkg.Types == nil || pkg.TypesInfo == nil {
+		t.Fatalf("GetPackagesDescList() package = %+v, want type checked arraymode package", pkg)
+	}
+	if len(pkg.Files) != len(wantFiles) {
+		t.Fatalf("GetPackagesDescList() got %d files, want %d", len(pkg.Files), len(wantFiles))
+	}
+	for _, fileDesc := range pkg.Files {
+		if fileDesc.Types != pkg.Types || fileDesc.TypesInfo != pkg.TypesInfo {
+			t.Errorf("%s: file type information does not match package", fileDesc.FileName)
+		}
+		fileDesc.Types, fileDesc.TypesInfo = nil, nil
+		relativePositions(t, fileDesc)
+		if !deepCompare(fileDesc, wantFiles[fileDesc.FileName], "fileDesc") {
+			t.Errorf("GetPackagesDescList() gotResult = %v, want %v", fileDesc, wantFiles[fileDesc.FileName])
+		}
+	}
+}
+
+// relativePositions 按包加载时注解位置为绝对路径 转换为相对当前目录的路径以便与按文件解析的结果比较
+func relativePositions(t *testing.T, fileDesc *FileDesc) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relative := func(occurrences []*AnnotationOccurrence) {
+		for _, occurrence := range occurrences {
+			if rel, err := filepath.Rel(wd, occurrence.Position.Filename); err == nil {
+				occurrence.Position.Filename = filepath.ToSlash(rel)
+			}
+		}
+	}
+	for _, structDesc := range fileDesc.Structs {
+		relative(structDesc.Occurrences)
+		for _, field := range structDesc.Fields {
+			relative(field.Occurrences)
+		}
+		for _, method := range structDesc.Methods {
+			relative(method.Occurrences)
+		}
+	}
+	for _, interfaceDesc := range fileDesc.Interfaces {
+		relative(interfaceDesc.Occurrences)
+		for _, method := range interfaceDesc.Methods {
+			relative(method.Occurrences)
+		}
+	}
+	for _, funcDesc := range fileDesc.Funcs {
+		relative(funcDesc.Occurrences)
+	}
+}
+
+func TestPackageMethods(t *testing.T) {
+	pkgs, err := GetPackagesDescList(AnnotationModeArray, "./test/data/receivers")
+	if err != nil {
+		t.Fatalf("GetPackagesDescList() error = %v", err)
+	}
+	want := map[string]string{
+		"Repo.Get":     "pointer",
+		"Repo.Len":     "value",
+		"Repo.Put":     "pointer",
+		"Service.Call": "value",
+	}
+	got := make(map[string]string)
+	for _, fileDesc := range pkgs[0].Files {
+		for _, structDesc := range fileDesc.Structs {
+			for _, method := range structDesc.Methods {
+				got[structDesc.Name+"."+method.Name] = string(method.ReceiverKind)
+			}
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetPackagesDescList() methods = %v, want %v", got, want)
+	}
+}
+
+func TestBuildConstraints(t *testing.T) {
+	fileDesc, err := GetFileDesc("test/data/buildtags/buildtags.go", AnnotationModeArray)
+	if err != nil {
+		t.Fatalf("GetFileDesc() error = %v", err)
+	}
+	pkgs, err := GetPackagesDescList(AnnotationModeArray, "./test/data/buildtags")
+	if err != nil {
+		t.Fatalf("GetPackagesDescList() error = %v", err)
+	}
+	// 不满足构建约束的文件中的方法不收集 与按包加载的结果一致
+	want := []string{"Close", "Open"}
+	for source, desc := range map[string]*FileDesc{"file": fileDesc, "package": pkgs[0].Files[0]} {
+		got := make([]string, 0)
+		for _, method := range desc.Structs[0].Methods {
+			got = append(got, method.Name)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s methods = %v, want %v", source, got, want)
+		}
+	}
+}
+
 func deepCompare(a, b interface{}, fieldName string) bool {
 	aVal := reflect.ValueOf(a)
 	bVal := reflect.ValueOf(b)
@@ -101,6 +427,13 @@ func deepCompare(a, b interface{}, fieldName string) bool {
 	tempEqual := true
 	switch aVal.Kind() {
 	case reflect.Ptr:
+		if aVal.IsNil() || bVal.IsNil() {
+			if aVal.IsNil() != bVal.IsNil() {
+				fmt.Printf("Field %s is different: a = %v, b = %v\n", fieldName, aVal.Interface(), bVal.Interface())
+				return false
+			}
+			return true
+		}
 		return deepCompare(aVal.Elem().Interface(), bVal.Elem().Interface(), fieldName)
 	case reflect.Struct:
 		for i := 0; i < aVal.NumField(); i++ {
@@ -140,3 +473,159 @@ func deepCompare(a, b interface{}, fieldName string) bool {
 	}
 	return equal
 }
+
+func TestAnnotationOccurrences(t *testing.T) {
+	fileDesc, err := GetFileDesc("test/data/order/order.go", AnnotationModeMap)
+	if err != nil {
+		t.Fatalf("GetFileDesc() error = %v", err)
+	}
+	type occurrence struct {
+		Name string
+		Raw  string
+		Line int
+	}
+	want := []occurrence{
+		{"Log", "Log", 7},
+		{"Auth", `Auth(role="admin")`, 8},
+		{"RateLimit", "RateLimit(qps=10)", 9},
+		{"Auth", `Auth(role="root")`, 10},
+	}
+	method := fileDesc.Structs[0].Methods[0]
+	got := make([]occurrence, 0)
+	for _, item := range method.Occurrences {
+		if item.Position.Filename != "test/data/order/order.go" {
+			t.Errorf("occurrence %s position = %s, want test/data/order/order.go", item.Name, item.Position)
+		}
+		got = append(got, occurrence{item.Name, item.Raw, item.Position.Line})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Occurrences = %v, want %v", got, want)
+	}
+	if method.Occurrences[3].Attributes["role"] != "root" || method.Occurrences[3].Values["role"].String != "root" {
+		t.Errorf("Occurrences[3] = %+v, want role root", method.Occurrences[3])
+	}
+	if len(method.Annotations["Auth"].Attributes) != 2 {
+		t.Errorf("Annotations[Auth] = %+v, want 2 attributes", method.Annotations["Auth"])
+	}
+}
+
+func TestFlattenEmbeds(t *testing.T) {
+	fileDesc, err := NewFileParser("test/data/embeds/embeds.go", &Options{Mode: AnnotationModeMap, FlattenEmbeds: true}).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	methods := func(list []*MethodDesc) []string {
+		names := make([]string, 0, len(list))
+		for _, method := range list {
+			names = append(names, method.Name+":"+method.PromotedFrom)
+		}
+		return names
+	}
+	// 结构体自身的方法覆盖嵌入类型的同名方法 多层嵌入的方法保留声明该方法的类型
+	wantStruct := []string{"Create:", "Name:BaseService", "Load:Loader", "Audit:base.Auditor"}
+	if got := methods(fileDesc.Structs[0].Methods); !reflect.DeepEqual(got, wantStruct) {
+		t.Errorf("struct methods = %v, want %v", got, wantStruct)
+	}
+	wantInterface := []string{"Save:", "Get:Reader", "Ping:base.Pinger"}
+	if got := methods(fileDesc.Interfaces[0].Methods); !reflect.DeepEqual(got, wantInterface) {
+		t.Errorf("interface methods = %v, want %v", got, wantInterface)
+	}
+	if _, ok := fileDesc.Interfaces[0].Imports["context"]; !ok {
+		t.Errorf("interface imports = %v, want imports of promoted methods", fileDesc.Interfaces[0].Imports)
+	}
+	if annotation := fileDesc.Structs[0].Methods[3].Annotations["log"]; annotation == nil || annotation.Attributes[0]["level"] != "info" {
+		t.Errorf("promoted method annotations = %v, want log(level=info)", fileDesc.Structs[0].Methods[3].Annotations)
+	}
+}
+
+func TestPackageAnnotations(t *testing.T) {
+	pkgs, err := GetPackagesDescList(AnnotationModeMap, "./test/data/pkgdoc")
+	if err != nil {
+		t.Fatalf("GetPackagesDescList() error = %v", err)
+	}
+	if len(pkgs) != 1 || len(pkgs[0].Files) != 2 {
+		t.Fatalf("GetPackagesDescList() got %v, want 1 package with 2 files", pkgs)
+	}
+	pkg := pkgs[0]
+	// 仅紧邻package子句的注释为包注释 多个文件的包注释合并
+	if got := pkg.Annotations["basePath"]; got == nil || got.Attributes[0]["path"] != "/api/v1" {
+		t.Errorf("package annotations = %v, want basePath", pkg.Annotations)
+	}
+	if got := pkg.Annotations["generate"]; got == nil || len(got.Attributes) != 2 {
+		t.Errorf("package annotation generate = %v, want 2 attribute groups", got)
+	}
+	if _, ok := pkg.Annotations["license"]; ok {
+		t.Errorf("package annotations = %v, want no license", pkg.Annotations)
+	}
+	wantComments := []string{`basePath(path="/api/v1")`, `generate(target="mocks")`, `generate(target="client")`}
+	if !reflect.DeepEqual(pkg.Comments, wantComments) {
+		t.Errorf("package comments = %v, want %v", pkg.Comments, wantComments)
+	}
+}
+
+func TestParamAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "handler.go")
+	source := `package handler
+
+// Get 获取
+// @param(name="id", in="path")
+// @param(name="missing", in="query")
+// @param(in="header")
+func Get(id int64) error {
+	return nil
+}
+`
+	if err := os.WriteFile(fileName, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fileDesc, err := NewParser(&Options{Mode: AnnotationModeMap, ParamAnnotation: ParamAnnotation}).GetFileDesc(fileName)
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 2 {
+		t.Fatalf("GetFileDesc() error = %v, want 2 diagnostics", err)
+	}
+	if list[0].Position.Line != 5 || list[1].Position.Line != 6 || list[0].Target != "Get" {
+		t.Errorf("GetFileDesc() diagnostics = %v, want lines 5 and 6 on Get", list)
+	}
+	param := fileDesc.Funcs[0].Params[0]
+	if got := param.Annotations["param"]; got == nil || len(got.Attributes) != 1 || got.Attributes[0]["in"] != "path" {
+		t.Errorf("param annotations = %v, want param(in=path)", param.Annotations)
+	}
+	// 绑定到参数的注解仍保留在方法上
+	if got := fileDesc.Funcs[0].Annotations["param"]; got == nil || len(got.Attributes) != 3 {
+		t.Errorf("func annotations = %v, want 3 param attribute groups", fileDesc.Funcs[0].Annotations)
+	}
+
+	// 默认不绑定 也不报告诊断信息
+	fileDesc, err = GetFileDesc(fileName, AnnotationModeMap)
+	if err != nil {
+		t.Fatalf("GetFileDesc() error = %v", err)
+	}
+	if param := fileDesc.Funcs[0].Params[0]; len(param.Annotations) != 0 {
+		t.Errorf("param annotations = %v, want none", param.Annotations)
+	}
+}
+
+func TestBindParamAnnotationsValues(t *testing.T) {
+	// 部分出现没有属性值时 Values仍与Attributes按下标对应
+	occurrences := []*AnnotationOccurrence{
+		{Name: ParamAnnotation, Attributes: map[string]string{"0": "id", "1": "path"}},
+		{Name: ParamAnnotation, Attributes: map[string]string{"name": "id", "in": "query"}, Values: map[string]*AnnotationValue{
+			"name": {Kind: AnnotationValueString, Raw: `"id"`, String: "id"},
+			"in":   {Kind: AnnotationValueString, Raw: `"query"`, String: "query"},
+		}},
+	}
+	params := []*Field{{Name: "id"}}
+	options := &Options{ParamAnnotation: ParamAnnotation}
+	bindParamAnnotations("Get", occurrences, params, options, newDiagnosticCollector(nil, ""))
+	annotation := params[0].Annotations[ParamAnnotation]
+	if annotation == nil || len(annotation.Values) != len(annotation.Attributes) {
+		t.Fatalf("param annotation = %+v, want Values aligned with Attributes", annotation)
+	}
+	if len(annotation.Values[0]) != 0 || annotation.Values[1]["in"].String != "query" {
+		t.Errorf("Values = %v, want empty then in=query", annotation.Values)
+	}
+	if got := attributeValue(annotation, 1, "in"); got.String != "query" {
+		t.Errorf("attributeValue(1, in) = %v, want query", got)
+	}
+}
@@ -3,10 +3,17 @@ package go_annotation
 import (
 	"fmt"
 	"go/ast"
-	"os/exec"
+	"go/token"
+	"go/types"
+	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/mod/modfile"
 )
 
 func getFileName(filePath string) string {
@@ -14,60 +21,211 @@ func getFileName(filePath string) string {
 	return parts[len(parts)-1]
 }
 
-// getModuleName 获取模块名
-func getModuleName() string {
-	cmd := exec.Command("go", "list", "-m")
-	output, err := cmd.Output()
-	if err != nil {
-		fmt.Println("Error finding module root:", err)
-		return ""
+type moduleInfo struct {
+	path string // 模块名
+	dir  string // 模块根目录
+}
+
+// moduleCache 目录 -> 所在模块 避免每个文件重复查找go.mod
+var moduleCache sync.Map
+
+// findModule 从目录向上查找go.mod 获取目录所在模块
+func findModule(dir string) (*moduleInfo, error) {
+	if cached, ok := moduleCache.Load(dir); ok {
+		return cached.(*moduleInfo), nil
+	}
+	var module *moduleInfo
+	goModPath := filepath.Join(dir, "go.mod")
+	if data, err := os.ReadFile(goModPath); err == nil {
+		modulePath := modfile.ModulePath(data)
+		if modulePath == "" {
+			return nil, fmt.Errorf("no module declaration in %s", goModPath)
+		}
+		module = &moduleInfo{path: modulePath, dir: dir}
+	} else {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("go.mod not found")
+		}
+		if module, err = findModule(parent); err != nil {
+			return nil, err
+		}
 	}
-	return strings.TrimSpace(string(output))
+	moduleCache.Store(dir, module)
+	return module, nil
 }
 
-// getFullPackageName 获取当前文件所在完整包名
-func getFullPackageName(moduleName string, filePath string) string {
+// getFullPackageName 获取当前文件所在完整包名 文件不在模块内时返回错误
+func getFullPackageName(filePath string) (string, error) {
 	absolutePath, err := filepath.Abs(filePath)
 	if err != nil {
-		fmt.Println("Error getting absolute path:", err)
-		return ""
+		return "", fmt.Errorf("cannot get absolute path: %s", err)
 	}
-	cmd := exec.Command("go", "list", "-m", "-f", "{{.Dir}}")
-	output, err := cmd.Output()
+	dirPath := filepath.Dir(absolutePath)
+	module, err := findModule(dirPath)
 	if err != nil {
-		fmt.Println("Error finding module root:", err)
-		return ""
+		return "", fmt.Errorf("cannot find module root: %s", err)
 	}
-	moduleRoot := strings.TrimSpace(string(output))
 
 	// 计算文件路径相对于模块根目录的路径
-	relativePath, err := filepath.Rel(moduleRoot, absolutePath)
+	relativePath, err := filepath.Rel(module.dir, dirPath)
 	if err != nil {
-		fmt.Println("Error calculating relative path:", err)
-		return ""
+		return "", fmt.Errorf("cannot calculate relative path: %s", err)
+	}
+	if relativePath == "." {
+		return module.path, nil
 	}
 	// 将相对路径转换为包的导入路径
-	dirPath := filepath.Dir(relativePath)
-	importPath := filepath.ToSlash(dirPath)
+	return module.path + "/" + filepath.ToSlash(relativePath), nil
+}
 
-	fullPackageName := moduleName + "/" + importPath
-	return fullPackageName
+// atComment 注解注释及其位置
+type atComment struct {
+	text string // 去除注释前缀及注解前缀后的内容
+	pos  token.Pos
 }
 
-func parseAtComments(commentGroup *ast.CommentGroup) (comments []string) {
-	comments = make([]string, 0)
-	if commentGroup != nil {
-		prefix := "// " + AnnotationPrefix
-		for _, com := range commentGroup.List {
-			if strings.HasPrefix(com.Text, prefix) {
-				commentText := strings.TrimPrefix(com.Text, prefix)
-				comments = append(comments, commentText)
+// commentLine 注释中的一行
+type commentLine struct {
+	text string    // 去除注释标记及开头空白后的内容
+	pos  token.Pos // 行首位置
+}
+
+// commentLines 将注释组拆分为行 去除每行开头的空白 块注释按行拆分 并去除每行开头的 *
+func commentLines(commentGroup *ast.CommentGroup) []*commentLine {
+	lines := make([]*commentLine, 0)
+	if commentGroup == nil {
+		return lines
+	}
+	for _, com := range commentGroup.List {
+		if strings.HasPrefix(com.Text, "//") {
+			lines = append(lines, &commentLine{text: strings.TrimLeftFunc(com.Text[2:], unicode.IsSpace), pos: com.Pos()})
+			continue
+		}
+		body := strings.TrimSuffix(strings.TrimPrefix(com.Text, "/*"), "*/")
+		offset := 2
+		for _, line := range strings.Split(body, "\n") {
+			trimmed := strings.TrimLeftFunc(line, unicode.IsSpace)
+			trimmed = strings.TrimLeftFunc(strings.TrimPrefix(trimmed, "*"), unicode.IsSpace)
+			pos := com.Pos() + token.Pos(offset+len(line)-len(trimmed))
+			lines = append(lines, &commentLine{text: strings.TrimRightFunc(trimmed, unicode.IsSpace), pos: pos})
+			offset += len(line) + 1
+		}
+	}
+	return lines
+}
+
+// parseAtCommentList 获取注释组中的注解
+// 注释标记后的内容去除空白后以注解标记(前缀及命名空间)开头时视为注解
+// 注解名后紧跟的括号未闭合时 后续行作为注解的延续 直到括号闭合、遇到下一个注解或注释组结束
+func parseAtCommentList(commentGroup *ast.CommentGroup, options *Options) []*atComment {
+	list := make([]*atComment, 0)
+	marker := options.marker()
+	lines := commentLines(commentGroup)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if !strings.HasPrefix(line.text, marker) {
+			continue
+		}
+		commentText := strings.TrimPrefix(line.text, marker)
+		depth := 0
+		if hasArgumentParen(commentText) {
+			depth = parenDepth(commentText)
+		}
+		for depth > 0 && i+1 < len(lines) && !strings.HasPrefix(lines[i+1].text, marker) {
+			i++
+			next := strings.TrimSpace(lines[i].text)
+			commentText = strings.TrimRight(commentText, " \t") + " " + next
+			depth += parenDepth(next)
+		}
+		list = append(list, &atComment{text: options.trimSuffix(commentText), pos: line.pos})
+	}
+	return list
+}
+
+// hasArgumentParen 注解名后是否紧跟括号 如 route(path="/a" 数组模式中属性内的括号如 desc smile :( 不视为参数的括号
+func hasArgumentParen(text string) bool {
+	index := strings.IndexByte(text, '(')
+	return index > 0 && strings.IndexFunc(text[:index], unicode.IsSpace) < 0
+}
+
+// parenDepth 计算文本中未闭合的括号数 引号内的括号不计入
+func parenDepth(text string) int {
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, c := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
 			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
 		}
 	}
+	return depth
+}
+
+func parseAtComments(commentGroup *ast.CommentGroup, options *Options) (comments []string) {
+	comments = make([]string, 0)
+	for _, com := range parseAtCommentList(commentGroup, options) {
+		comments = append(comments, com.text)
+	}
 	return comments
 }
 
+// parseAnnotations 解析注释中的注解 注解解析器实现了AnnotationChecker时 格式错误的注解记录到诊断信息中
+// target: 注解所属目标 用于诊断信息
+// occurrences: 按声明顺序排列的每次出现的注解
+func parseAnnotations(target string, options *Options, diagnostics *diagnosticCollector, commentGroups ...*ast.CommentGroup) (comments []string, annotations map[string]*Annotation, occurrences []*AnnotationOccurrence) {
+	comments = make([]string, 0)
+	checker, _ := options.AnnotationParser.(AnnotationChecker)
+	for _, commentGroup := range commentGroups {
+		for _, com := range parseAtCommentList(commentGroup, options) {
+			if checker != nil {
+				if err := checker.Check(com.text); err != nil {
+					diagnostics.add(com.pos, target, "invalid annotation %s%s: %s", options.marker(), com.text, err)
+				}
+			}
+			diagnostics.record(target, com)
+			comments = append(comments, com.text)
+			occurrences = append(occurrences, parseOccurrences(com, options, diagnostics)...)
+		}
+	}
+	return comments, options.AnnotationParser.Parse(comments), occurrences
+}
+
+// parseOccurrences 解析单条注释中的注解 自定义解析器可能从一条注释中解析出多个注解 按名称排序
+func parseOccurrences(com *atComment, options *Options, diagnostics *diagnosticCollector) []*AnnotationOccurrence {
+	parsed := options.AnnotationParser.Parse([]string{com.text})
+	names := make([]string, 0, len(parsed))
+	for name := range parsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	occurrences := make([]*AnnotationOccurrence, 0, len(names))
+	for _, name := range names {
+		occurrence := &AnnotationOccurrence{Name: name, Raw: com.text, Position: diagnostics.position(com.pos)}
+		if annotation := parsed[name]; len(annotation.Attributes) > 0 {
+			occurrence.Attributes = annotation.Attributes[0]
+			if len(annotation.Values) > 0 {
+				occurrence.Values = annotation.Values[0]
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences
+}
+
 func parseDescription(name string, commentGroup *ast.CommentGroup) (description string) {
 	if commentGroup == nil {
 		return ""
@@ -85,27 +243,15 @@ func parseDescription(name string, commentGroup *ast.CommentGroup) (description
 
 }
 
-func splitComment(comment string) []string {
-	re := regexp.MustCompile("[\\s　]") // Use half-width space and full-width space as separators
-	commentSlice := re.Split(comment, -1)
-	var filteredSlice []string
-	for _, item := range commentSlice {
-		trimmedItem := strings.TrimSpace(item)
-		if trimmedItem != "" {
-			filteredSlice = append(filteredSlice, trimmedItem)
-		}
-	}
-	return filteredSlice
-}
-
-func parseField(field *ast.Field) (fieldDesc *Field, err error) {
+func parseField(field *ast.Field, resolver *typeResolver) (fieldDesc *Field, err error) {
 	fieldDesc = &Field{}
-	if field.Names != nil || len(field.Names) > 0 {
+	if len(field.Names) > 0 {
 		fieldDesc.Name = field.Names[0].Name
 	}
 	fieldDesc.DataType = exprToString(field.Type)
-	if strings.Contains(fieldDesc.DataType, "*") {
-		fieldDesc.RealDataType = strings.Replace(fieldDesc.DataType, "*", "", -1)
+	// 仅最外层为指针时视为指针 []*T、func(*T)等不是指针
+	if starExpr, ok := field.Type.(*ast.StarExpr); ok {
+		fieldDesc.RealDataType = exprToString(starExpr.X)
 		fieldDesc.IsPtr = true
 	} else {
 		fieldDesc.RealDataType = fieldDesc.DataType
@@ -127,9 +273,193 @@ func parseField(field *ast.Field) (fieldDesc *Field, err error) {
 		}
 	}
 	fieldDesc.PackageName = packageName
+	fieldDesc.Type = resolver.resolve(field.Type)
 	return fieldDesc, err
 }
 
+// parseSignature 解析函数的参数及返回值 a, b int 会被拆分为两个参数
+// 未命名或名为 _ 的参数、返回值按位置生成名称 参数为 p0、p1 返回值为 r0、r1 与已有名称冲突时追加 _
+func parseSignature(funcType *ast.FuncType, resolver *typeResolver) (params []*Field, results []*Field, err error) {
+	names := make(map[string]bool)
+	for _, fieldList := range []*ast.FieldList{funcType.Params, funcType.Results} {
+		if fieldList == nil {
+			continue
+		}
+		for _, field := range fieldList.List {
+			for _, name := range field.Names {
+				names[name.Name] = true
+			}
+		}
+	}
+	if params, err = parseParams(funcType.Params, resolver, "p", names); err != nil {
+		return nil, nil, err
+	}
+	if results, err = parseParams(funcType.Results, resolver, "r", names); err != nil {
+		return nil, nil, err
+	}
+	return params, results, nil
+}
+
+func parseParams(fieldList *ast.FieldList, resolver *typeResolver, prefix string, names map[string]bool) ([]*Field, error) {
+	fields := make([]*Field, 0)
+	if fieldList == nil {
+		return fields, nil
+	}
+	for _, param := range fieldList.List {
+		paramNames := make([]string, 0, len(param.Names))
+		for _, name := range param.Names {
+			paramNames = append(paramNames, name.Name)
+		}
+		if len(paramNames) == 0 {
+			paramNames = append(paramNames, "")
+		}
+		for _, name := range paramNames {
+			field, err := parseField(param, resolver)
+			if err != nil {
+				return nil, err
+			}
+			if name == "" || name == "_" {
+				name = prefix + strconv.Itoa(len(fields))
+				for names[name] {
+					name += "_"
+				}
+				names[name] = true
+			}
+			field.Name = name
+			_, field.IsVariadic = param.Type.(*ast.Ellipsis)
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// parseTypeParams 解析类型参数 [K comparable, V any] 会被拆分为两个参数
+func parseTypeParams(fieldList *ast.FieldList) []*TypeParam {
+	typeParams := make([]*TypeParam, 0)
+	if fieldList == nil {
+		return typeParams
+	}
+	for _, field := range fieldList.List {
+		constraint := exprToString(field.Type)
+		for _, name := range field.Names {
+			typeParams = append(typeParams, &TypeParam{Name: name.Name, Constraint: constraint})
+		}
+	}
+	return typeParams
+}
+
+// receiverType 获取方法接收者的类型名 支持 T、*T、T[K]、*T[K, V]
+func receiverType(expr ast.Expr) (typeName string, isPtr bool) {
+	if starExpr, ok := expr.(*ast.StarExpr); ok {
+		isPtr = true
+		expr = starExpr.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		typeName = t.Name
+	case *ast.IndexExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			typeName = ident.Name
+		}
+	case *ast.IndexListExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			typeName = ident.Name
+		}
+	case *ast.ParenExpr:
+		return receiverType(t.X)
+	}
+	return typeName, isPtr
+}
+
+// receiverTypeParams 获取方法接收者的类型参数 参数名取自接收者 约束按位置取自类型声明
+func receiverTypeParams(expr ast.Expr, declared []*TypeParam) []*TypeParam {
+	typeParams := make([]*TypeParam, 0)
+	if starExpr, ok := expr.(*ast.StarExpr); ok {
+		expr = starExpr.X
+	}
+	if parenExpr, ok := expr.(*ast.ParenExpr); ok {
+		return receiverTypeParams(parenExpr.X, declared)
+	}
+	var indices []ast.Expr
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		indices = t.Indices
+	}
+	for i, index := range indices {
+		typeParam := &TypeParam{Name: exprToString(index)}
+		if i < len(declared) {
+			typeParam.Constraint = declared[i].Constraint
+		}
+		typeParams = append(typeParams, typeParam)
+	}
+	return typeParams
+}
+
+// embeddedName 获取嵌入字段的字段名 如 *pkg.Base 的字段名为 Base
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	default:
+		return ""
+	}
+}
+
+// parseStructTag 解析结构体标签 规则与reflect.StructTag一致 格式错误的部分会被忽略
+func parseStructTag(tag string) map[string]string {
+	tags := make(map[string]string)
+	for tag != "" {
+		// skip leading space
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+		// scan to colon
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := tag[:i]
+		tag = tag[i+1:]
+		// scan quoted string to find value
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			break
+		}
+		tags[name] = value
+		tag = tag[i+1:]
+	}
+	return tags
+}
+
+// exprToString 将类型表达式转换为源码形式 如 map[string][]*pkg.T、func(int, ...string) error、List[K, V]
 func exprToString(expr ast.Expr) string {
 
 	switch t := expr.(type) {
@@ -140,10 +470,48 @@ func exprToString(expr ast.Expr) string {
 	case *ast.StarExpr:
 		// pointer
 		return "*" + exprToString(t.X)
+	case *ast.ParenExpr:
+		return "(" + exprToString(t.X) + ")"
+	case *ast.BasicLit:
+		// 数组长度
+		return t.Value
 	case *ast.ArrayType:
-		return "[]" + exprToString(t.Elt)
+		if t.Len == nil {
+			return "[]" + exprToString(t.Elt)
+		}
+		return "[" + exprToString(t.Len) + "]" + exprToString(t.Elt)
+	case *ast.Ellipsis:
+		// 可变参数 ...T 或数组长度 [...]T
+		if t.Elt == nil {
+			return "..."
+		}
+		return "..." + exprToString(t.Elt)
 	case *ast.MapType:
 		return "map[" + exprToString(t.Key) + "]" + exprToString(t.Value)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + exprToString(t.Value)
+		case ast.RECV:
+			return "<-chan " + exprToString(t.Value)
+		default:
+			// chan (<-chan T) 需要括号
+			if ch, ok := t.Value.(*ast.ChanType); ok && ch.Dir == ast.RECV {
+				return "chan (" + exprToString(t.Value) + ")"
+			}
+			return "chan " + exprToString(t.Value)
+		}
+	case *ast.FuncType:
+		return "func" + signatureToString(t)
+	case *ast.IndexExpr:
+		// 泛型实例化 List[int]
+		return exprToString(t.X) + "[" + exprToString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		indices := make([]string, 0, len(t.Indices))
+		for _, index := range t.Indices {
+			indices = append(indices, exprToString(index))
+		}
+		return exprToString(t.X) + "[" + strings.Join(indices, ", ") + "]"
 	case *ast.StructType:
 		var fields []string
 		for _, field := range t.Fields.List {
@@ -151,6 +519,11 @@ func exprToString(expr ast.Expr) string {
 			for _, name := range field.Names {
 				names = append(names, name.Name)
 			}
+			if len(names) == 0 {
+				// 嵌入字段
+				fields = append(fields, exprToString(field.Type))
+				continue
+			}
 			fields = append(fields, strings.Join(names, ", ")+" "+exprToString(field.Type))
 		}
 		return "struct{" + strings.Join(fields, "; ") + "}"
@@ -160,14 +533,55 @@ func exprToString(expr ast.Expr) string {
 		}
 		var methods []string
 		for _, method := range t.Methods.List {
-			var names []string
-			for _, name := range method.Names {
-				names = append(names, name.Name)
+			funcType, ok := method.Type.(*ast.FuncType)
+			if len(method.Names) == 0 || !ok {
+				// 嵌入接口或类型约束
+				methods = append(methods, exprToString(method.Type))
+				continue
 			}
-			methods = append(methods, strings.Join(names, ", ")+" "+exprToString(method.Type))
+			methods = append(methods, method.Names[0].Name+signatureToString(funcType))
 		}
 		return "interface{" + strings.Join(methods, "; ") + "}"
+	case *ast.UnaryExpr:
+		// 近似约束 ~int
+		return t.Op.String() + exprToString(t.X)
+	case *ast.BinaryExpr:
+		// 联合约束 ~int | ~string
+		return exprToString(t.X) + " " + t.Op.String() + " " + exprToString(t.Y)
 	default:
-		return fmt.Sprintf("%T", t)
+		return types.ExprString(expr)
+	}
+}
+
+// signatureToString 函数签名 如 (a, b int, opts ...string) (int, error) 不含func关键字
+func signatureToString(funcType *ast.FuncType) string {
+	signature := "(" + fieldListToString(funcType.Params) + ")"
+	if funcType.Results == nil || len(funcType.Results.List) == 0 {
+		return signature
+	}
+	results := fieldListToString(funcType.Results)
+	if len(funcType.Results.List) == 1 && len(funcType.Results.List[0].Names) == 0 {
+		return signature + " " + results
+	}
+	return signature + " (" + results + ")"
+}
+
+// fieldListToString 参数列表 a, b int, c string
+func fieldListToString(fieldList *ast.FieldList) string {
+	if fieldList == nil {
+		return ""
+	}
+	fields := make([]string, 0, len(fieldList.List))
+	for _, field := range fieldList.List {
+		var names []string
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		if len(names) == 0 {
+			fields = append(fields, exprToString(field.Type))
+			continue
+		}
+		fields = append(fields, strings.Join(names, ", ")+" "+exprToString(field.Type))
 	}
+	return strings.Join(fields, ", ")
 }
@@ -0,0 +1,133 @@
+package go_annotation
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestParseAtCommentList(t *testing.T) {
+	src := `package p
+
+// A 多行注解
+// @route(path="/a",
+//     method="GET")
+// @auth
+type A struct{}
+
+/*
+ * B 块注释
+ * @route(path="/b")
+ */
+type B struct{}
+
+// C 未闭合的注解延续到注释结束
+// @route(path="/c",
+// method="POST"
+type C struct{}
+
+// D 数组模式属性中的括号不延续
+// @desc smile :(
+// @auth admin
+type D struct{}
+
+// E 遇到下一个注解时停止延续
+// @route(path="/e",
+// @auth
+type E struct{}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type comment struct {
+		Text string
+		Line int
+	}
+	want := [][]comment{
+		{{`route(path="/a", method="GET")`, 4}, {"auth", 6}},
+		{{`route(path="/b")`, 11}},
+		{{`route(path="/c", method="POST"`, 16}},
+		{{"desc smile :(", 21}, {"auth admin", 22}},
+		{{`route(path="/e",`, 26}, {"auth", 27}},
+	}
+	for i, w := range want {
+		got := make([]comment, 0)
+		for _, com := range parseAtCommentList(file.Comments[i], (&Options{}).withDefaults()) {
+			got = append(got, comment{com.text, fset.Position(com.pos).Line})
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("parseAtCommentList() group %d = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestParseAtCommentListSyntax(t *testing.T) {
+	src := `package p
+
+// A 注解语法测试 邮箱 a@b.com 不是注解
+//@Get
+//   @Post
+//	@Put
+// @@Delete
+// +gen:Patch
+// #[Head]
+// @api.Options
+// @rpc.Options
+type A struct{}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		options *Options
+		want    []string
+	}{
+		{name: "默认前缀及任意空白", options: &Options{}, want: []string{"Get", "Post", "Put", "@Delete", "api.Options", "rpc.Options"}},
+		{name: "双字符前缀", options: &Options{AnnotationPrefix: "@@"}, want: []string{"Delete"}},
+		{name: "自定义前缀", options: &Options{AnnotationPrefix: "+gen:"}, want: []string{"Patch"}},
+		{name: "前缀及后缀", options: &Options{AnnotationPrefix: "#[", AnnotationSuffix: "]"}, want: []string{"Head"}},
+		{name: "命名空间", options: &Options{Namespace: "api"}, want: []string{"Options"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAtComments(file.Comments[0], tt.options.withDefaults())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAtComments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExprToString(t *testing.T) {
+	tests := []string{
+		"map[string][]*pkg.T",
+		"[...]int",
+		"[N]byte",
+		"chan<- int",
+		"<-chan (<-chan int)",
+		"chan (<-chan int)",
+		"func(a, b int, opts ...string) (n int, err error)",
+		"func() func() error",
+		"List[int]",
+		"pkg.Map[string, List[V]]",
+		"interface{io.Reader; Close() error}",
+		"struct{io.Reader; a, b int}",
+		"~int | ~string",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := exprToString(expr); got != tt {
+				t.Errorf("exprToString() = %s, want %s", got, tt)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+// Package cli go-annotation命令行的实现 注册自定义注解模式后调用Run即可复用全部子命令
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	annotation "github.com/celt237/go-annotation"
+	"github.com/celt237/go-annotation/generate"
+)
+
+const usage = `go-annotation 解析go源码中的注解
+
+Usage:
+    go-annotation <command> [flags] <file|directory>...
+
+Commands:
+    parse     解析注解并以json格式输出
+    list      列出带有注解的结构体、接口、方法、函数、类型及常量、变量
+    check     检查源码能否被正确解析
+    generate  根据模版生成代码
+
+使用 "go-annotation <command> -h" 查看命令参数
+`
+
+// Run 执行命令 args不含程序名 返回进程退出码
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "parse":
+		err = runParse(args[1:], stdout, stderr)
+	case "list":
+		err = runList(args[1:], stdout, stderr)
+	case "check":
+		err = runCheck(args[1:], stdout, stderr)
+	case "generate":
+		err = runGenerate(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command: %s\n\n%s", args[0], usage)
+		return 2
+	}
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(stderr, err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// newFlagSet 创建子命令参数集合 所有子命令均支持 --mode
+func newFlagSet(name string, mode *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(mode, "mode", string(annotation.AnnotationModeArray), "注解模式 "+modeNames())
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-annotation %s [flags] <file|directory|pattern>...\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseMode 校验注解模式 支持所有已注册的模式
+func parseMode(mode string) (annotation.AnnotationMode, error) {
+	if _, err := annotation.NewAnnotationParser(annotation.AnnotationMode(mode)); err != nil {
+		return "", err
+	}
+	return annotation.AnnotationMode(mode), nil
+}
+
+func modeNames() string {
+	names := make([]string, 0)
+	for _, mode := range annotation.AnnotationModes() {
+		names = append(names, string(mode))
+	}
+	return strings.Join(names, "|")
+}
+
+// loadFiles 解析命令行传入的文件、目录或包匹配模式(如 ./...) 未传入时默认为当前目录
+// 注解错误等诊断信息不会中断解析 与已解析的文件一起返回
+func loadFiles(paths []string, mode annotation.AnnotationMode) ([]*annotation.FileDesc, annotation.ErrorList, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	filesDesc := make([]*annotation.FileDesc, 0)
+	var diagnostics annotation.ErrorList
+	// collect 收集诊断信息 非诊断信息的错误直接返回
+	collect := func(path string, err error) error {
+		if err == nil {
+			return nil
+		}
+		var list annotation.ErrorList
+		if errors.As(err, &list) {
+			diagnostics = append(diagnostics, list...)
+			return nil
+		}
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "...") {
+			pkgs, err := annotation.GetPackagesDescList(mode, path)
+			if err = collect(path, err); err != nil {
+				return nil, nil, err
+			}
+			for _, pkg := range pkgs {
+				filesDesc = append(filesDesc, pkg.Files...)
+			}
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if info.IsDir() {
+			list, err := annotation.GetFilesDescList(path, mode)
+			if err = collect(path, err); err != nil {
+				return nil, nil, err
+			}
+			for _, fileDesc := range list {
+				if fileDesc != nil {
+					filesDesc = append(filesDesc, fileDesc)
+				}
+			}
+		} else {
+			fileDesc, err := annotation.GetFileDesc(path, mode)
+			if err = collect(path, err); err != nil {
+				return nil, nil, err
+			}
+			if fileDesc != nil {
+				filesDesc = append(filesDesc, fileDesc)
+			}
+		}
+	}
+	return filesDesc, diagnostics, nil
+}
+
+// printDiagnostics 每行输出一条诊断信息
+func printDiagnostics(w io.Writer, diagnostics annotation.ErrorList) {
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(w, diagnostic)
+	}
+}
+
+func runParse(args []string, stdout, stderr io.Writer) error {
+	var mode, output string
+	fs := newFlagSet("parse", &mode)
+	fs.StringVar(&output, "o", "", "输出文件 默认输出到标准输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	annotationMode, err := parseMode(mode)
+	if err != nil {
+		return err
+	}
+	filesDesc, diagnostics, err := loadFiles(fs.Args(), annotationMode)
+	if err != nil {
+		return err
+	}
+	printDiagnostics(stderr, diagnostics)
+	data, err := json.MarshalIndent(filesDesc, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if output == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(output, data, 0644)
+}
+
+func runList(args []string, stdout, stderr io.Writer) error {
+	var mode string
+	fs := newFlagSet("list", &mode)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	annotationMode, err := parseMode(mode)
+	if err != nil {
+		return err
+	}
+	filesDesc, diagnostics, err := loadFiles(fs.Args(), annotationMode)
+	if err != nil {
+		return err
+	}
+	printDiagnostics(stderr, diagnostics)
+	for _, fileDesc := range filesDesc {
+		fmt.Fprintf(stdout, "%s (%s)%s\n", fileDesc.FileName, fileDesc.FullPackageName, annotationNames(fileDesc.Annotations))
+		for _, structDesc := range fileDesc.Structs {
+			if structDesc == nil {
+				continue
+			}
+			fmt.Fprintf(stdout, "  struct %s%s\n", structDesc.Name, annotationNames(structDesc.Annotations))
+			for _, field := range structDesc.Fields {
+				if len(field.Annotations) > 0 {
+					fmt.Fprintf(stdout, "    field %s%s\n", field.Name, annotationNames(field.Annotations))
+				}
+			}
+			printMethods(stdout, structDesc.Methods)
+		}
+		for _, interfaceDesc := range fileDesc.Interfaces {
+			if interfaceDesc == nil {
+				continue
+			}
+			fmt.Fprintf(stdout, "  interface %s%s\n", interfaceDesc.Name, annotationNames(interfaceDesc.Annotations))
+			printMethods(stdout, interfaceDesc.Methods)
+		}
+		for _, funcDesc := range fileDesc.Funcs {
+			fmt.Fprintf(stdout, "  func %s%s\n", funcDesc.Name, annotationNames(funcDesc.Annotations))
+		}
+		for _, typeDesc := range fileDesc.NamedTypes {
+			fmt.Fprintf(stdout, "  type %s%s\n", typeDesc.Name, annotationNames(typeDesc.Annotations))
+		}
+		printValueGroups(stdout, "const", fileDesc.Consts)
+		printValueGroups(stdout, "var", fileDesc.Vars)
+	}
+	return nil
+}
+
+func printValueGroups(w io.Writer, kind string, groups []*annotation.ValueGroupDesc) {
+	for _, group := range groups {
+		if len(group.Annotations) > 0 {
+			fmt.Fprintf(w, "  %s group%s\n", kind, annotationNames(group.Annotations))
+		}
+		for _, value := range group.Values {
+			if len(value.Annotations) > 0 {
+				fmt.Fprintf(w, "  %s %s%s\n", kind, value.Name, annotationNames(value.Annotations))
+			}
+		}
+	}
+}
+
+func printMethods(w io.Writer, methods []*annotation.MethodDesc) {
+	for _, method := range methods {
+		fmt.Fprintf(w, "    method %s%s\n", method.Name, annotationNames(method.Annotations))
+	}
+}
+
+// annotationNames 按名称排序输出注解
+func annotationNames(annotations map[string]*annotation.Annotation) string {
+	if len(annotations) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(annotations))
+	for name := range annotations {
+		names = append(names, annotation.AnnotationPrefix+name)
+	}
+	sort.Strings(names)
+	return " " + strings.Join(names, " ")
+}
+
+func runCheck(args []string, stdout, stderr io.Writer) error {
+	var mode string
+	fs := newFlagSet("check", &mode)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	annotationMode, err := parseMode(mode)
+	if err != nil {
+		return err
+	}
+	filesDesc, diagnostics, err := loadFiles(fs.Args(), annotationMode)
+	if err != nil {
+		return err
+	}
+	if len(diagnostics) > 0 {
+		printDiagnostics(stdout, diagnostics)
+		return fmt.Errorf("%d errors found in %d files", len(diagnostics), len(filesDesc))
+	}
+	fmt.Fprintf(stdout, "ok, %d files parsed\n", len(filesDesc))
+	return nil
+}
+
+func runGenerate(args []string, stdout io.Writer) error {
+	var mode, configFile, templateFile, output, suffix, scope string
+	fs := newFlagSet("generate", &mode)
+	fs.StringVar(&configFile, "config", "", "yaml配置文件 指定后忽略其余参数")
+	fs.StringVar(&templateFile, "template", "", "模版文件 未指定-config时必传")
+	fs.StringVar(&output, "output", ".", "生成代码所在目录")
+	fs.StringVar(&suffix, "suffix", "", "生成文件后缀 默认为_gen.go")
+	fs.StringVar(&scope, "scope", "", "模版执行范围 file|struct|interface 默认为file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	configs := make([]*generate.Config, 0)
+	if configFile != "" {
+		config, err := generate.LoadConfig(configFile)
+		if err != nil {
+			return err
+		}
+		configs = append(configs, config)
+	} else {
+		if templateFile == "" {
+			return fmt.Errorf("flag -template or -config is required")
+		}
+		annotationMode, err := parseMode(mode)
+		if err != nil {
+			return err
+		}
+		paths := fs.Args()
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		for _, path := range paths {
+			configs = append(configs, &generate.Config{
+				SourcePath:   path,
+				GenFilePath:  output,
+				TemplateFile: templateFile,
+				Mode:         annotationMode,
+				Scope:        generate.Scope(scope),
+				FileSuffix:   suffix,
+			})
+		}
+	}
+	for _, config := range configs {
+		generator, err := generate.NewGenerator(config)
+		if err != nil {
+			return err
+		}
+		genFiles, err := generator.Generate()
+		if err != nil {
+			return err
+		}
+		for _, genFile := range genFiles {
+			fmt.Fprintln(stdout, genFile)
+		}
+	}
+	return nil
+}
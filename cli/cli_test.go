@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	annotation "github.com/celt237/go-annotation"
+)
+
+// 注册自定义注解模式后 命令行可直接使用
+func init() {
+	if err := annotation.RegisterAnnotationParser("custom-map", func() annotation.AnnotationParser { return &annotation.MapAnnotationParser{} }); err != nil {
+		panic(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  []string
+	}{
+		{
+			name:     "无子命令",
+			args:     []string{},
+			wantCode: 2,
+		},
+		{
+			name:     "未知子命令",
+			args:     []string{"unknown"},
+			wantCode: 2,
+		},
+		{
+			name:     "未知注解模式",
+			args:     []string{"parse", "--mode", "unknown", "../test/data/mapmode"},
+			wantCode: 1,
+		},
+		{
+			name:     "map模式解析",
+			args:     []string{"parse", "--mode", "map", "../test/data/mapmode/mapmode_single_struct.go"},
+			wantCode: 0,
+			wantOut:  []string{`"Name": "StructOne"`, `"id": "1"`},
+		},
+		{
+			name:     "列出注解",
+			args:     []string{"list", "--mode", "map", "../test/data/mapmode"},
+			wantCode: 0,
+			wantOut:  []string{"struct StructOne @annotation", "interface InterfaceTwo @annotation", "method Method4 @annotation"},
+		},
+		{
+			name:     "自定义注解模式",
+			args:     []string{"list", "--mode", "custom-map", "../test/data/mapmode/mapmode_single_struct.go"},
+			wantCode: 0,
+			wantOut:  []string{"struct StructOne @annotation"},
+		},
+		{
+			name:     "按包列出注解",
+			args:     []string{"list", "--mode", "map", "../test/data/mapmode/..."},
+			wantCode: 0,
+			wantOut:  []string{"mapmode_single_struct.go (github.com/celt237/go-annotation/test/data/mapmode)", "struct StructOne @annotation"},
+		},
+		{
+			name:     "检查",
+			args:     []string{"check", "../test/data/arraymode"},
+			wantCode: 0,
+			wantOut:  []string{"ok, 3 files parsed"},
+		},
+		{
+			name:     "列出文件级注解",
+			args:     []string{"list", "--mode", "map", "../test/data/pkgdoc"},
+			wantCode: 0,
+			wantOut:  []string{"doc.go (github.com/celt237/go-annotation/test/data/pkgdoc) @basePath @generate", "pkgdoc.go (github.com/celt237/go-annotation/test/data/pkgdoc) @generate @license"},
+		},
+		{
+			name:     "列出类型及常量",
+			args:     []string{"list", "--mode", "map", "../test/data/enums/enums.go"},
+			wantCode: 0,
+			wantOut:  []string{"type Status @enum", "const StatusPending @label", "var group @config"},
+		},
+		{
+			name:     "检查注解错误",
+			args:     []string{"check", "--mode", "map", "../test/data/diagnostics"},
+			wantCode: 1,
+			wantOut:  []string{"diagnostics.go:6:1: BadStruct: invalid annotation", "diagnostics.go:19:1: BadStruct.Method2: invalid annotation"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := Run(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("Run() code = %d, want %d, stderr = %s", code, tt.wantCode, stderr.String())
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("Run() output does not contain %q:\n%s", want, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunGenerate(t *testing.T) {
+	dir := t.TempDir()
+	templateFile := filepath.Join(dir, "service.tmpl")
+	tmpl := `package {{.File.PackageName}}
+{{range .File.Structs}}
+// {{.Name}}Methods {{.Description}}
+var {{.Name}}Methods = []string{ {{range .Methods}}"{{.Name}}",{{end}} }
+{{end}}`
+	if err := os.WriteFile(templateFile, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	args := []string{"generate", "-mode", "map", "-template", templateFile, "-output", dir, "../test/data/mapmode/mapmode_single_struct.go"}
+	if code := Run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run() code = %d, stderr = %s", code, stderr.String())
+	}
+	code, err := os.ReadFile(filepath.Join(dir, "mapmode_single_struct_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `var StructOneMethods = []string{"Method1", "Method2", "Method3", "Method4"}`
+	if !strings.Contains(string(code), want) {
+		t.Errorf("generated code does not contain %q:\n%s", want, code)
+	}
+}
+
+func TestRunGenerateIntoSource(t *testing.T) {
+	dir := t.TempDir()
+	// 与 //go:generate 的常见用法一致 输出目录即源码目录
+	files := map[string]string{
+		"go.mod":       "module svc\n",
+		"svc.go":       "package svc\n\n// Service 服务\n// @service\ntype Service struct{}\n",
+		"service.tmpl": "package {{.File.PackageName}}\n{{range .File.Structs}}\n// {{.Name}}Name 名称\nvar {{.Name}}Name = \"{{.Name}}\"\n{{end}}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"generate", "-template", filepath.Join(dir, "service.tmpl"), "-output", dir, dir}
+	for i := 0; i < 2; i++ {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("Run() code = %d, stderr = %s", code, stderr.String())
+		}
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*_gen_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) > 0 {
+		t.Errorf("generate read its own output: %v", matches)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "svc_gen.go")); err != nil {
+		t.Errorf("generated file: %v", err)
+	}
+}
@@ -1 +1,11 @@
-package go_annotation
+package main
+
+import (
+	"os"
+
+	"github.com/celt237/go-annotation/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
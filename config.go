@@ -1,5 +1,75 @@
 package go_annotation
 
+import (
+	"go/ast"
+	"strings"
+)
+
 const AnnotationPrefix = "@"
 
-var currentAnnotationMode = AnnotationModeArray
+const ParamAnnotation = "param" // 常用的参数注解名称 设置到Options.ParamAnnotation后开启绑定
+
+// Options 解析选项
+type Options struct {
+	Mode             AnnotationMode             // 注解模式 默认为array
+	AnnotationPrefix string                     // 注解前缀 默认为@ 如 +gen:、@@、#[
+	AnnotationSuffix string                     // 注解后缀 可选 如前缀为#[时设置为]
+	Namespace        string                     // 命名空间 设置后仅识别 @命名空间.名称 形式的注解 如 @api.Get 解析后的注解名称不含命名空间
+	AnnotationParser AnnotationParser           // 自定义注解解析器 设置后忽略Mode
+	FileFilter       func(fileName string) bool // 文件过滤 返回false的文件不解析
+	TypeFilter       func(typeName string) bool // 结构体、接口过滤 返回false的类型不解析
+	Dir              string                     // 按包加载时的工作目录 默认为当前目录
+	BuildTags        []string                   // 按包加载时使用的构建标签
+	Registry         *Registry                  // 注解定义注册表 设置后校验注解 未定义的注解、属性及类型错误记录为诊断信息
+	FlattenEmbeds    bool                       // 是否将嵌入类型的方法提升到结构体、接口的方法列表 仅支持当前模块内声明的嵌入类型
+	ParamAnnotation  string                     // 绑定到参数的方法注解名称 如param 为空时不绑定 按属性name或第一个位置属性匹配参数名
+
+	err error // 填充默认值时的错误 如未注册的注解模式 由各解析器的Parse返回
+}
+
+// withDefaults 复制选项并填充默认值 调用方传入的选项不会被修改
+func (o *Options) withDefaults() *Options {
+	options := &Options{}
+	if o != nil {
+		*options = *o
+	}
+	if options.Mode == "" {
+		options.Mode = AnnotationModeArray
+	}
+	if options.AnnotationPrefix == "" {
+		options.AnnotationPrefix = AnnotationPrefix
+	}
+	if options.AnnotationParser == nil {
+		options.AnnotationParser, options.err = getAnnotationParser(options.Mode)
+	}
+	return options
+}
+
+// marker 注解标记 注释行去除空白后以该标记开头时视为注解
+func (o *Options) marker() string {
+	if o.Namespace == "" {
+		return o.AnnotationPrefix
+	}
+	return o.AnnotationPrefix + o.Namespace + "."
+}
+
+// trimSuffix 去除注解后缀
+func (o *Options) trimSuffix(text string) string {
+	if o.AnnotationSuffix == "" {
+		return text
+	}
+	return strings.TrimSuffix(strings.TrimRight(text, " \t"), o.AnnotationSuffix)
+}
+
+// hasAnnotations 注释中是否包含注解
+func (o *Options) hasAnnotations(commentGroup *ast.CommentGroup) bool {
+	return len(parseAtCommentList(commentGroup, o)) > 0
+}
+
+func (o *Options) acceptFile(fileName string) bool {
+	return o.FileFilter == nil || o.FileFilter(fileName)
+}
+
+func (o *Options) acceptType(typeName string) bool {
+	return o.TypeFilter == nil || o.TypeFilter(typeName)
+}
@@ -0,0 +1,157 @@
+package go_annotation
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic  诊断信息 描述源码中无法解析的内容
+type Diagnostic struct {
+	Position token.Position // 位置 无法确定时为零值
+	Target   string         // 目标 如 StructOne、StructOne.Method1 为空表示整个文件
+	Message  string         // 错误信息
+}
+
+func (d *Diagnostic) Error() string {
+	var builder strings.Builder
+	if d.Position.IsValid() {
+		builder.WriteString(d.Position.String())
+		builder.WriteString(": ")
+	} else if d.Position.Filename != "" {
+		builder.WriteString(d.Position.Filename)
+		builder.WriteString(": ")
+	}
+	if d.Target != "" {
+		builder.WriteString(d.Target)
+		builder.WriteString(": ")
+	}
+	builder.WriteString(d.Message)
+	return builder.String()
+}
+
+// ErrorList  诊断信息列表 解析过程中收集的所有诊断信息通过该类型以error返回
+type ErrorList []*Diagnostic
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	default:
+		return fmt.Sprintf("%s (and %d more errors)", l[0].Error(), len(l)-1)
+	}
+}
+
+// Sort 按文件、行、列排序
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := l[i].Position, l[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// Err 列表为空时返回nil 避免返回非nil的空列表
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// diagnosticCollector 诊断信息收集器 由文件内的各解析器共享
+type diagnosticCollector struct {
+	fset        *token.FileSet
+	filePath    string
+	list        ErrorList
+	annotations map[string][]*atComment // 目标 -> 注解及其位置 用于校验注解
+}
+
+func newDiagnosticCollector(fset *token.FileSet, filePath string) *diagnosticCollector {
+	return &diagnosticCollector{fset: fset, filePath: filePath, annotations: make(map[string][]*atComment)}
+}
+
+// position 获取位置 pos无效时仅包含文件名
+func (c *diagnosticCollector) position(pos token.Pos) token.Position {
+	if c.fset != nil && pos.IsValid() {
+		return c.fset.Position(pos)
+	}
+	return token.Position{Filename: c.filePath}
+}
+
+// add 记录诊断信息
+func (c *diagnosticCollector) add(pos token.Pos, target string, format string, args ...interface{}) {
+	c.list = append(c.list, &Diagnostic{Position: c.position(pos), Target: target, Message: fmt.Sprintf(format, args...)})
+}
+
+// addAt 在已知位置记录诊断信息
+func (c *diagnosticCollector) addAt(position token.Position, target string, format string, args ...interface{}) {
+	c.list = append(c.list, &Diagnostic{Position: position, Target: target, Message: fmt.Sprintf(format, args...)})
+}
+
+// record 记录目标上的注解
+func (c *diagnosticCollector) record(target string, com *atComment) {
+	c.annotations[target] = append(c.annotations[target], com)
+}
+
+// toDiagnostics 将错误转换为诊断信息
+func toDiagnostics(err error, filePath string) ErrorList {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case ErrorList:
+		return e
+	case *Diagnostic:
+		return ErrorList{e}
+	case scanner.ErrorList:
+		list := make(ErrorList, 0, len(e))
+		for _, item := range e {
+			list = append(list, &Diagnostic{Position: item.Pos, Message: item.Msg})
+		}
+		return list
+	case *scanner.Error:
+		return ErrorList{{Position: e.Pos, Message: e.Msg}}
+	default:
+		return ErrorList{{Position: token.Position{Filename: filePath}, Message: err.Error()}}
+	}
+}
+
+// packageDiagnostic 将go list报告的包错误转换为诊断信息 位置格式为 file:line:col 或 file:line
+func packageDiagnostic(pos string, msg string) *Diagnostic {
+	diagnostic := &Diagnostic{Message: msg}
+	if pos == "" || pos == "-" {
+		return diagnostic
+	}
+	filename := pos
+	numbers := make([]int, 0, 2)
+	for len(numbers) < 2 {
+		i := strings.LastIndex(filename, ":")
+		if i < 0 {
+			break
+		}
+		n, e := strconv.Atoi(filename[i+1:])
+		if e != nil {
+			break
+		}
+		numbers = append(numbers, n)
+		filename = filename[:i]
+	}
+	diagnostic.Position.Filename = filename
+	switch len(numbers) {
+	case 2:
+		diagnostic.Position.Line, diagnostic.Position.Column = numbers[1], numbers[0]
+	case 1:
+		diagnostic.Position.Line = numbers[0]
+	}
+	return diagnostic
+}
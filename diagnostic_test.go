@@ -0,0 +1,135 @@
+package go_annotation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	want := []struct {
+		line   int
+		target string
+	}{
+		{6, "BadStruct"},
+		{10, "BadStruct.Name"},
+		{19, "BadStruct.Method2"},
+	}
+	tests := []struct {
+		name  string
+		parse func() (*FileDesc, error)
+	}{
+		{
+			name: "按文件解析",
+			parse: func() (*FileDesc, error) {
+				return GetFileDesc("test/data/diagnostics/diagnostics.go", AnnotationModeMap)
+			},
+		},
+		{
+			name: "按包解析",
+			parse: func() (*FileDesc, error) {
+				pkgs, err := GetPackagesDescList(AnnotationModeMap, "./test/data/diagnostics")
+				if len(pkgs) != 1 || len(pkgs[0].Files) != 1 {
+					t.Fatalf("GetPackagesDescList() got %v, want 1 package with 1 file", pkgs)
+				}
+				return pkgs[0].Files[0], err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileDesc, err := tt.parse()
+			var list ErrorList
+			if !errors.As(err, &list) {
+				t.Fatalf("parse() error = %v, want ErrorList", err)
+			}
+			if len(list) != len(want) {
+				t.Fatalf("parse() got %d diagnostics, want %d: %v", len(list), len(want), list)
+			}
+			for i, w := range want {
+				d := list[i]
+				if filepath.Base(d.Position.Filename) != "diagnostics.go" || d.Position.Line != w.line || d.Target != w.target {
+					t.Errorf("diagnostic[%d] = %s, want line %d target %s", i, d, w.line, w.target)
+				}
+			}
+			// 错误的注解不影响其余内容的解析
+			if fileDesc == nil || len(fileDesc.Structs) != 1 || len(fileDesc.Interfaces) != 1 {
+				t.Fatalf("parse() fileDesc = %v, want 1 struct and 1 interface", fileDesc)
+			}
+			if methods := fileDesc.Structs[0].Methods; len(methods) != 2 || methods[0].Annotations["get"] == nil {
+				t.Errorf("parse() struct methods = %v, want Method1 and Method2", methods)
+			}
+			if methods := fileDesc.Interfaces[0].Methods; len(methods) != 1 || methods[0].Name != "Read" {
+				t.Errorf("parse() interface methods = %v, want Read", methods)
+			}
+			if embeds := fileDesc.Interfaces[0].Embeds; len(embeds) != 1 || embeds[0].Name != "io.Closer" || embeds[0].Resolved {
+				t.Errorf("parse() interface embeds = %v, want unresolved io.Closer", embeds)
+			}
+		})
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "broken.go")
+	if err := os.WriteFile(fileName, []byte("package broken\n\nfunc Broken( {\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fileDesc, err := GetFileDesc(fileName, AnnotationModeMap)
+	var list ErrorList
+	if !errors.As(err, &list) || fileDesc != nil {
+		t.Fatalf("GetFileDesc() = %v, %v, want ErrorList", fileDesc, err)
+	}
+	if list[0].Position.Filename != fileName || list[0].Position.Line != 3 {
+		t.Errorf("GetFileDesc() diagnostic = %s, want %s:3", list[0], fileName)
+	}
+}
+
+func TestNoModule(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "nomod.go")
+	source := "package nomod\n\n// Service 模块外的文件\n// @service\ntype Service struct{}\n"
+	if err := os.WriteFile(fileName, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 模块外的文件照常解析 完整包名为空
+	fileDesc, err := GetFileDesc(fileName, AnnotationModeArray)
+	if err != nil {
+		t.Fatalf("GetFileDesc() error = %v", err)
+	}
+	if fileDesc == nil || fileDesc.FullPackageName != "" || len(fileDesc.Structs) != 1 {
+		t.Errorf("GetFileDesc() fileDesc = %v, want 1 struct without full package name", fileDesc)
+	}
+}
+
+func TestMapAnnotationParserBadInput(t *testing.T) {
+	parser := &MapAnnotationParser{}
+	comments := []string{"(", ")", "a(", "a)", "a(b", "a(b=\"c", "a(b=\"c\"", "a((", "a(b=\"c\"))", "a(b=\"c\") d"}
+	for _, comment := range comments {
+		if parser.Check(comment) == nil {
+			t.Errorf("Check(%q) = nil, want error", comment)
+		}
+		// 格式错误的注解不应导致panic
+		parser.Parse([]string{comment})
+	}
+}
+
+func TestPackageDiagnostic(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  string
+		want string
+	}{
+		{name: "行列", pos: "a/b.go:3:7", want: "a/b.go:3:7: msg"},
+		{name: "仅行", pos: "a/b.go:3", want: "a/b.go:3: msg"},
+		{name: "无位置", pos: "", want: "msg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := packageDiagnostic(tt.pos, "msg").Error(); got != tt.want {
+				t.Errorf("packageDiagnostic() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
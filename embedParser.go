@@ -0,0 +1,217 @@
+package go_annotation
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// embedDecl 嵌入类型的声明
+type embedDecl struct {
+	typeSpec     *ast.TypeSpec
+	genDecl      *ast.GenDecl
+	file         *ast.File
+	packageFiles []*ast.File // 声明所在包的所有文件
+	packagePath  string      // 声明所在包的完整包名
+}
+
+// embedResolver 查找嵌入类型的声明 仅支持当前包及当前模块内的其他包
+type embedResolver struct {
+	fset         *token.FileSet
+	filePath     string      // 当前文件路径 用于查找所在模块
+	packageFiles []*ast.File // 当前包的所有文件
+	packagePath  string      // 当前包完整包名
+
+	packages map[string][]*ast.File // 导入路径 -> 包内文件 避免重复解析
+	visiting map[*ast.TypeSpec]bool // 正在展开的类型 防止循环嵌入
+}
+
+func newEmbedResolver(fset *token.FileSet, filePath string, packageFiles []*ast.File, packagePath string) *embedResolver {
+	if fset == nil {
+		fset = token.NewFileSet()
+	}
+	return &embedResolver{
+		fset:         fset,
+		filePath:     filePath,
+		packageFiles: packageFiles,
+		packagePath:  packagePath,
+		packages:     make(map[string][]*ast.File),
+		visiting:     make(map[*ast.TypeSpec]bool),
+	}
+}
+
+// lookup 查找嵌入类型的声明 未找到时返回nil
+// imports: 嵌入所在文件的导入信息 packageFiles: 嵌入所在包的所有文件
+func (r *embedResolver) lookup(expr ast.Expr, imports map[string]*ImportDesc, packageFiles []*ast.File, packagePath string) *embedDecl {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return r.lookup(t.X, imports, packageFiles, packagePath)
+	case *ast.ParenExpr:
+		return r.lookup(t.X, imports, packageFiles, packagePath)
+	case *ast.IndexExpr:
+		return r.lookup(t.X, imports, packageFiles, packagePath)
+	case *ast.IndexListExpr:
+		return r.lookup(t.X, imports, packageFiles, packagePath)
+	case *ast.Ident:
+		return findTypeDecl(t.Name, packageFiles, packagePath)
+	case *ast.SelectorExpr:
+		ident, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		imp, ok := imports[ident.Name]
+		if !ok {
+			return nil
+		}
+		files := r.modulePackage(imp.Path)
+		return findTypeDecl(t.Sel.Name, files, imp.Path)
+	default:
+		return nil
+	}
+}
+
+// modulePackage 解析当前模块内指定导入路径的包 不在当前模块内时返回nil
+func (r *embedResolver) modulePackage(importPath string) []*ast.File {
+	if files, ok := r.packages[importPath]; ok {
+		return files
+	}
+	var files []*ast.File
+	absolutePath, err := filepath.Abs(r.filePath)
+	if err != nil {
+		return nil
+	}
+	module, err := findModule(filepath.Dir(absolutePath))
+	if err == nil && (importPath == module.path || strings.HasPrefix(importPath, module.path+"/")) {
+		dir := filepath.Join(module.dir, strings.TrimPrefix(importPath, module.path))
+		parsed, _ := parseDirFiles(r.fset, dir, "")
+		for path, file := range parsed {
+			if !strings.HasSuffix(path, "_test.go") && matchBuildContext(path) {
+				files = append(files, file)
+			}
+		}
+	}
+	r.packages[importPath] = files
+	return files
+}
+
+// findTypeDecl 在包内文件中查找类型声明
+func findTypeDecl(name string, files []*ast.File, packagePath string) *embedDecl {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok && typeSpec.Name.Name == name {
+					return &embedDecl{typeSpec: typeSpec, genDecl: genDecl, file: file, packageFiles: files, packagePath: packagePath}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseEmbed 解析嵌入类型 找到声明时附带声明上的注释及注解
+func parseEmbed(expr ast.Expr, resolver *typeResolver, decl *embedDecl, options *Options) *EmbedDesc {
+	field, _ := parseField(&ast.Field{Type: expr}, resolver)
+	embed := &EmbedDesc{
+		Name:        exprToString(embeddedType(expr)),
+		DataType:    field.DataType,
+		PackageName: field.PackageName,
+		IsPtr:       field.IsPtr,
+		Type:        field.Type,
+	}
+	if decl == nil {
+		return embed
+	}
+	embed.Resolved = true
+	// 声明上的注解错误在解析声明所在文件时报告 此处忽略
+	diagnostics := newDiagnosticCollector(nil, "")
+	embed.Comments, embed.Annotations, _ = parseAnnotations(decl.typeSpec.Name.Name, options, diagnostics, decl.genDecl.Doc)
+	embed.Description = parseDescription(decl.typeSpec.Name.Name, decl.genDecl.Doc)
+	return embed
+}
+
+// embeddedType 去除指针及类型实参 如 *pkg.List[int] 返回 pkg.List
+func embeddedType(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedType(t.X)
+	case *ast.ParenExpr:
+		return embeddedType(t.X)
+	case *ast.IndexExpr:
+		return embeddedType(t.X)
+	case *ast.IndexListExpr:
+		return embeddedType(t.X)
+	default:
+		return expr
+	}
+}
+
+// promotedMethods 获取嵌入类型的方法 包括嵌入类型自身嵌入的类型的方法
+// 方法的PromotedFrom为声明该方法的类型 嵌入类型的导入信息合并到imports
+func (r *embedResolver) promotedMethods(embed *EmbedDesc, decl *embedDecl, options *Options, imports map[string]*ImportDesc) []*MethodDesc {
+	if decl == nil || r.visiting[decl.typeSpec] {
+		return nil
+	}
+	r.visiting[decl.typeSpec] = true
+	defer delete(r.visiting, decl.typeSpec)
+	fileImports := getFileImports(decl.file)
+	resolver := &typeResolver{imports: fileImports, packagePath: decl.packagePath, packageName: decl.file.Name.Name}
+	diagnostics := newDiagnosticCollector(nil, "")
+	var methods []*MethodDesc
+	var declImports map[string]*ImportDesc
+	switch decl.typeSpec.Type.(type) {
+	case *ast.StructType:
+		parser := NewStructParser(decl.typeSpec.Name.Name, decl.typeSpec, decl.genDecl, decl.file, fileImports, options)
+		parser.resolver = resolver
+		parser.diagnostics = diagnostics
+		parser.packageFiles = decl.packageFiles
+		parser.embeds = r
+		structDesc, err := parser.Parse()
+		if err != nil || structDesc == nil {
+			return nil
+		}
+		methods, declImports = structDesc.Methods, structDesc.Imports
+	case *ast.InterfaceType:
+		parser := NewInterfaceParser(decl.typeSpec.Name.Name, decl.typeSpec, decl.genDecl, fileImports, options)
+		parser.resolver = resolver
+		parser.diagnostics = diagnostics
+		parser.comments = decl.file.Comments
+		parser.packageFiles = decl.packageFiles
+		parser.embeds = r
+		interfaceDesc, err := parser.Parse()
+		if err != nil || interfaceDesc == nil {
+			return nil
+		}
+		methods, declImports = interfaceDesc.Methods, interfaceDesc.Imports
+	}
+	for _, method := range methods {
+		if method.PromotedFrom == "" {
+			method.PromotedFrom = embed.Name
+		}
+	}
+	for name, imp := range declImports {
+		if _, ok := imports[name]; !ok {
+			imports[name] = imp
+		}
+	}
+	return methods
+}
+
+// appendPromoted 追加提升的方法 与已有方法同名的方法被覆盖 不追加
+func appendPromoted(methods []*MethodDesc, promoted []*MethodDesc) []*MethodDesc {
+	names := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		names[method.Name] = true
+	}
+	for _, method := range promoted {
+		if !names[method.Name] {
+			names[method.Name] = true
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
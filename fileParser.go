@@ -3,24 +3,48 @@ package go_annotation
 import (
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 type FileParser struct {
 	filePath string
+	options  *Options
+
+	// 以下字段按包或按目录加载时有值 否则在Parse时从磁盘读取并解析文件
+	fset            *token.FileSet
+	file            *ast.File
+	packageFiles    []*ast.File // 同包的所有文件 包含当前文件 用于跨文件收集方法
+	fullPackageName string
+	types           *types.Package
+	typesInfo       *types.Info
+	checker         *dirChecker // 未按包加载时对同包文件做类型检查 按目录加载时同目录的文件共用
 }
 
+// GetFileParser 使用默认选项创建文件解析器
 func GetFileParser(filePath string) *FileParser {
-	return &FileParser{filePath: filePath}
+	return NewFileParser(filePath, nil)
+}
+
+// NewFileParser 创建文件解析器
+// options: 解析选项 为nil时使用默认选项
+func NewFileParser(filePath string, options *Options) *FileParser {
+	return &FileParser{filePath: filePath, options: options.withDefaults()}
 }
 
 func GetFileNames(directory string) ([]string, error) {
 	fileNames := make([]string, 0)
 	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		// 目录不存在或无法读取时info为nil
+		if err != nil {
+			return err
+		}
 		if !info.IsDir() && strings.HasSuffix(info.Name(), ".go") {
 			fileNames = append(fileNames, path)
 		}
@@ -29,60 +53,322 @@ func GetFileNames(directory string) ([]string, error) {
 	return fileNames, err
 }
 
+// newPackageFileParser 使用按包加载后的语法树及类型信息创建文件解析器
+func newPackageFileParser(filePath string, fset *token.FileSet, file *ast.File, packageFiles []*ast.File, pkg *PackageDesc, options *Options) *FileParser {
+	return &FileParser{
+		filePath:        filePath,
+		options:         options.withDefaults(),
+		fset:            fset,
+		file:            file,
+		packageFiles:    packageFiles,
+		fullPackageName: pkg.PkgPath,
+		types:           pkg.Types,
+		typesInfo:       pkg.TypesInfo,
+	}
+}
+
+// Parse 解析文件
+// 无法解析的内容会被跳过并记录为诊断信息 以ErrorList返回 此时仍会返回已解析的部分
 func (f *FileParser) Parse() (*FileDesc, error) {
-	// parse file
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, f.filePath, nil, parser.ParseComments)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse file: %s", err)
+	if f.options.err != nil {
+		return nil, f.options.err
 	}
+	node := f.file
+	if node == nil {
+		// parse file
+		var err error
+		f.fset = token.NewFileSet()
+		node, err = parser.ParseFile(f.fset, f.filePath, nil, parser.ParseComments)
+		if err != nil {
+			return nil, toDiagnostics(err, f.filePath)
+		}
+		// 同目录下的其他文件仅用于收集方法 解析失败时忽略
+		path := filepath.Clean(f.filePath)
+		files, _ := parseDirFiles(f.fset, filepath.Dir(path), path)
+		files[path] = node
+		f.packageFiles = samePackageFiles(files, path)
+	}
+	if f.typesInfo == nil && f.checker == nil {
+		f.checker = newDirChecker(f.fset, filepath.Dir(f.filePath), f.options)
+	}
+	diagnostics := newDiagnosticCollector(f.fset, f.filePath)
 	importsDic, err := f.parseImport(node)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse import: %s", err)
 	}
+	fullPackageName := f.fullPackageName
+	if fullPackageName == "" {
+		// 文件不在模块内时完整包名及同包类型的导入路径为空 不视为错误
+		fullPackageName, _ = getFullPackageName(f.filePath)
+	}
+	values := &packageValues{fileParser: f, packagePath: fullPackageName, options: f.options}
+	resolver := &typeResolver{
+		info:        values.typesInfo(),
+		imports:     importsDic,
+		packagePath: fullPackageName,
+		packageName: node.Name.Name,
+	}
+	embeds := newEmbedResolver(f.fset, f.filePath, f.packageFiles, fullPackageName)
 	structs := make([]*StructDesc, 0)
 	interfaces := make([]*InterfaceDesc, 0)
+	namedTypes := make([]*NamedTypeDesc, 0)
+	consts := make([]*ValueGroupDesc, 0)
+	vars := make([]*ValueGroupDesc, 0)
 	genDecls, err := getGenDecls(node)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get service: %s", err)
 	}
-	if len(genDecls) == 0 {
-		return nil, nil
+	funcs := f.parseFuncs(node, importsDic, resolver, diagnostics)
+	fileName := filepath.Base(f.filePath)
+	comments, annotations, occurrences := parseAnnotations(fileName, f.options, diagnostics, leadingComments(node)...)
+	if len(genDecls) == 0 && len(funcs) == 0 && len(comments) == 0 {
+		return nil, diagnostics.list.Err()
 	}
 	for _, genDecl := range genDecls {
+		if genDecl.Tok == token.CONST || genDecl.Tok == token.VAR {
+			// 无注解的常量组、变量组不需要类型检查
+			if !hasValueAnnotations(genDecl, f.options) {
+				continue
+			}
+			valueParser := NewValueParser(genDecl, importsDic, f.options)
+			valueParser.resolver = resolver
+			valueParser.diagnostics = diagnostics
+			valueParser.info = values.typesInfo()
+			group, err := valueParser.Parse()
+			if err != nil {
+				diagnostics.add(genDecl.Pos(), genDecl.Tok.String(), "failed to parse %s: %s", genDecl.Tok, err)
+			} else if group != nil && group.IsConst {
+				consts = append(consts, group)
+			} else if group != nil {
+				vars = append(vars, group)
+			}
+			continue
+		}
 		for _, spec := range genDecl.Specs {
 			if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+				if !f.options.acceptType(typeSpec.Name.Name) {
+					continue
+				}
 				if _, ok := typeSpec.Type.(*ast.StructType); ok {
-					structParser := NewStructParser(typeSpec.Name.Name, typeSpec, genDecl, node, importsDic)
+					structParser := NewStructParser(typeSpec.Name.Name, typeSpec, genDecl, node, importsDic, f.options)
+					structParser.resolver = resolver
+					structParser.packageFiles = f.packageFiles
+					structParser.diagnostics = diagnostics
+					structParser.embeds = embeds
 					structDesc, err := structParser.Parse()
 					if err != nil {
-						return nil, fmt.Errorf("failed to parse struct: %s", err)
+						diagnostics.add(typeSpec.Pos(), typeSpec.Name.Name, "failed to parse struct: %s", err)
+					} else if structDesc != nil {
+						structs = append(structs, structDesc)
 					}
-					structs = append(structs, structDesc)
 				} else if _, ok := typeSpec.Type.(*ast.InterfaceType); ok {
-					interfaceParser := NewInterfaceParser(typeSpec.Name.Name, typeSpec, genDecl, importsDic)
+					interfaceParser := NewInterfaceParser(typeSpec.Name.Name, typeSpec, genDecl, importsDic, f.options)
+					interfaceParser.resolver = resolver
+					interfaceParser.diagnostics = diagnostics
+					interfaceParser.comments = node.Comments
+					interfaceParser.packageFiles = f.packageFiles
+					interfaceParser.embeds = embeds
 					interfaceDesc, err := interfaceParser.Parse()
 					if err != nil {
-						return nil, fmt.Errorf("failed to parse interface: %s", err)
+						diagnostics.add(typeSpec.Pos(), typeSpec.Name.Name, "failed to parse interface: %s", err)
+					} else if interfaceDesc != nil {
+						interfaces = append(interfaces, interfaceDesc)
+					}
+				} else {
+					namedTypeParser := NewNamedTypeParser(typeSpec, genDecl, importsDic, f.options)
+					namedTypeParser.resolver = resolver
+					namedTypeParser.diagnostics = diagnostics
+					namedTypeParser.consts = values.consts
+					namedTypeDesc, err := namedTypeParser.Parse()
+					if err != nil {
+						diagnostics.add(typeSpec.Pos(), typeSpec.Name.Name, "failed to parse type: %s", err)
+					} else if namedTypeDesc != nil {
+						namedTypes = append(namedTypes, namedTypeDesc)
 					}
-					interfaces = append(interfaces, interfaceDesc)
 				}
 			}
 		}
 
 	}
-	fileInfo, err := os.Stat(f.filePath)
-	moduleName := getModuleName()
 	fileDesc := &FileDesc{
-		FileName:        fileInfo.Name(),
+		FileName:        fileName,
 		PackageName:     node.Name.Name,
-		FullPackageName: getFullPackageName(moduleName, f.filePath),
+		FullPackageName: fullPackageName,
 		//RelativePath: "", // todo unimplemented
-		Imports:    importsDic,
-		Structs:    structs,
-		Interfaces: interfaces,
+		Comments:    comments,
+		Annotations: annotations,
+		Occurrences: occurrences,
+		Imports:     importsDic,
+		Structs:     structs,
+		Interfaces:  interfaces,
+		Funcs:       funcs,
+		NamedTypes:  namedTypes,
+		Consts:      consts,
+		Vars:        vars,
+		Types:       f.types,
+		TypesInfo:   f.typesInfo,
 	}
-	return fileDesc, nil
+	f.validateAnnotations(fileDesc, diagnostics)
+	diagnostics.list.Sort()
+	return fileDesc, diagnostics.list.Err()
+}
+
+// leadingComments 获取package子句之前的注释 如版权声明、构建约束及包注释
+func leadingComments(file *ast.File) []*ast.CommentGroup {
+	groups := make([]*ast.CommentGroup, 0)
+	for _, group := range file.Comments {
+		if group.Pos() < file.Package {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// packageValues 延迟获取同包的类型检查信息及常量 仅在需要时计算
+type packageValues struct {
+	fileParser  *FileParser
+	packagePath string
+	options     *Options
+
+	info      *types.Info
+	constList []*ValueDesc
+}
+
+// typesInfo 类型检查信息 未按包加载时对同包文件做类型检查
+func (p *packageValues) typesInfo() *types.Info {
+	if p.fileParser.typesInfo != nil {
+		return p.fileParser.typesInfo
+	}
+	if p.info == nil {
+		p.info = p.fileParser.checker.check(p.fileParser.packageFiles, p.packagePath)
+	}
+	return p.info
+}
+
+// dirChecker 按文件解析时对同包文件做类型检查 同一目录的文件共用导入器及类型检查结果
+type dirChecker struct {
+	fset     *token.FileSet
+	importer *dirImporter
+	infos    map[string]*types.Info // 同包文件路径 -> 类型检查信息
+}
+
+func newDirChecker(fset *token.FileSet, dir string, options *Options) *dirChecker {
+	return &dirChecker{fset: fset, importer: newDirImporter(fset, dir, options), infos: make(map[string]*types.Info)}
+}
+
+// check 对同包文件做类型检查 文件相同时只检查一次
+func (c *dirChecker) check(files []*ast.File, packagePath string) *types.Info {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, c.fset.Position(file.Package).Filename)
+	}
+	key := strings.Join(paths, "\n")
+	if info, ok := c.infos[key]; ok {
+		return info
+	}
+	info := checkPackage(c.fset, files, packagePath, c.importer)
+	c.infos[key] = info
+	return info
+}
+
+// consts 同包所有文件中的常量 按文件及声明顺序
+func (p *packageValues) consts() []*ValueDesc {
+	if p.constList != nil {
+		return p.constList
+	}
+	p.constList = make([]*ValueDesc, 0)
+	for _, file := range p.fileParser.packageFiles {
+		imports := getFileImports(file)
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			valueParser := NewValueParser(genDecl, imports, p.options)
+			valueParser.resolver = &typeResolver{info: p.typesInfo(), imports: imports, packagePath: p.packagePath, packageName: file.Name.Name}
+			// 注解错误在解析常量所在文件时报告 此处忽略
+			valueParser.diagnostics = newDiagnosticCollector(p.fileParser.fset, "")
+			valueParser.info = p.typesInfo()
+			values, _ := valueParser.parseValues()
+			p.constList = append(p.constList, values...)
+		}
+	}
+	return p.constList
+}
+
+// parseFuncs 解析带有注解的包级函数
+func (f *FileParser) parseFuncs(file *ast.File, importsDic map[string]*ImportDesc, resolver *typeResolver, diagnostics *diagnosticCollector) []*FuncDesc {
+	funcs := make([]*FuncDesc, 0)
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil {
+			continue
+		}
+		funcParser := NewFuncParser(funcDecl, importsDic, f.options)
+		funcParser.resolver = resolver
+		funcParser.diagnostics = diagnostics
+		funcParser.comments = file.Comments
+		funcDesc, err := funcParser.Parse()
+		if err != nil {
+			diagnostics.add(funcDecl.Pos(), funcDecl.Name.Name, "failed to parse func: %s", err)
+		} else if funcDesc != nil {
+			funcs = append(funcs, funcDesc)
+		}
+	}
+	return funcs
+}
+
+// parseDirFiles 解析目录下的所有go文件(不含子目录)
+// skip: 跳过的文件 返回文件路径 -> 语法树及文件路径 -> 解析错误
+func parseDirFiles(fset *token.FileSet, dir string, skip string) (map[string]*ast.File, map[string]error) {
+	files := make(map[string]*ast.File)
+	errs := make(map[string]error)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return files, errs
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || path == skip {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			errs[path] = err
+			continue
+		}
+		files[path] = file
+	}
+	return files, errs
+}
+
+// samePackageFiles 获取与指定文件同包的文件 按文件路径排序
+// 测试文件仅在指定文件本身为测试文件时包含 其他文件须满足当前平台的构建约束
+func samePackageFiles(files map[string]*ast.File, filePath string) []*ast.File {
+	file := files[filePath]
+	isTest := strings.HasSuffix(filePath, "_test.go")
+	paths := make([]string, 0, len(files))
+	for path, item := range files {
+		if item.Name.Name != file.Name.Name || (!isTest && strings.HasSuffix(path, "_test.go")) {
+			continue
+		}
+		if path != filePath && !matchBuildContext(path) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	result := make([]*ast.File, 0, len(paths))
+	for _, path := range paths {
+		result = append(result, files[path])
+	}
+	return result
+}
+
+// matchBuildContext 文件是否满足当前平台的构建约束 包括文件名后缀及 //go:build 与包加载时一致
+func matchBuildContext(path string) bool {
+	match, err := build.Default.MatchFile(filepath.Dir(path), filepath.Base(path))
+	return err == nil && match
 }
 
 func getGenDecls(file *ast.File) (list []*ast.GenDecl, err error) {
@@ -96,6 +382,11 @@ func getGenDecls(file *ast.File) (list []*ast.GenDecl, err error) {
 }
 
 func (f *FileParser) parseImport(file *ast.File) (result map[string]*ImportDesc, err error) {
+	return getFileImports(file), nil
+}
+
+// getFileImports 获取文件的导入信息 导入名 -> 导入信息
+func getFileImports(file *ast.File) (result map[string]*ImportDesc) {
 	result = make(map[string]*ImportDesc)
 	ast.Inspect(file, func(n ast.Node) bool {
 		if importSpec, ok := n.(*ast.ImportSpec); ok {
@@ -119,5 +410,5 @@ func (f *FileParser) parseImport(file *ast.File) (result map[string]*ImportDesc,
 		}
 		return true
 	})
-	return result, err
+	return result
 }
@@ -0,0 +1,67 @@
+package go_annotation
+
+import (
+	"go/ast"
+)
+
+type FuncParser struct {
+	funcDecl    *ast.FuncDecl
+	fileImports map[string]*ImportDesc
+	options     *Options
+	resolver    *typeResolver
+	diagnostics *diagnosticCollector
+	comments    []*ast.CommentGroup // 函数所在文件的注释 用于解析参数上的注释
+}
+
+func NewFuncParser(funcDecl *ast.FuncDecl,
+	fileImports map[string]*ImportDesc,
+	options *Options) *FuncParser {
+	return &FuncParser{
+		funcDecl:    funcDecl,
+		fileImports: fileImports,
+		options:     options.withDefaults(),
+		resolver:    &typeResolver{imports: fileImports},
+		diagnostics: newDiagnosticCollector(nil, ""),
+	}
+}
+
+// Parse 解析函数 方法或不含注解的函数返回nil
+func (s *FuncParser) Parse() (*FuncDesc, error) {
+	if s.options.err != nil {
+		return nil, s.options.err
+	}
+	if s.funcDecl.Recv != nil || !s.options.hasAnnotations(s.funcDecl.Doc) {
+		return nil, nil
+	}
+	comments, annotations, occurrences := parseAnnotations(s.funcDecl.Name.Name, s.options, s.diagnostics, s.funcDecl.Doc)
+	if len(comments) == 0 {
+		return nil, nil
+	}
+	funcDesc := &FuncDesc{
+		Name:        s.funcDecl.Name.Name,
+		Description: parseDescription(s.funcDecl.Name.Name, s.funcDecl.Doc),
+		Comments:    comments,
+		Annotations: annotations,
+		Occurrences: occurrences,
+		TypeParams:  parseTypeParams(s.funcDecl.Type.TypeParams),
+	}
+	var err error
+	if funcDesc.Params, funcDesc.Results, err = parseSignature(s.funcDecl.Type, s.resolver.withTypeParams(funcDesc.TypeParams)); err != nil {
+		return nil, err
+	}
+	parseParamComments(funcDesc.Name, s.funcDecl.Type.Params, funcDesc.Params, s.comments, s.options, s.diagnostics)
+	bindParamAnnotations(funcDesc.Name, funcDesc.Occurrences, funcDesc.Params, s.options, s.diagnostics)
+	funcDesc.Imports = s.parserImports(funcDesc)
+	return funcDesc, nil
+}
+
+func (s *FuncParser) parserImports(funcDesc *FuncDesc) (imports map[string]*ImportDesc) {
+	imports = make(map[string]*ImportDesc)
+	fields := make([]*Field, 0)
+	fields = append(fields, funcDesc.Params...)
+	fields = append(fields, funcDesc.Results...)
+	for _, field := range fields {
+		addFieldImports(imports, s.fileImports, field)
+	}
+	return imports
+}
@@ -1,7 +1,27 @@
 package generate
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	annotation "github.com/celt237/go-annotation"
+	"gopkg.in/yaml.v3"
+)
+
+// Scope 模版执行范围
+type Scope string
+
+const (
+	ScopeFile      Scope = "file"      // 每个文件执行一次模版
+	ScopeStruct    Scope = "struct"    // 每个结构体执行一次模版
+	ScopeInterface Scope = "interface" // 每个接口执行一次模版
+)
+
+const defaultFileSuffix = "_gen.go"
+
 type Config struct {
-	// service文件所在目录 必传
+	// service文件所在目录 必传 也可以是文件或包匹配模式(如 ./...)
 	SourcePath string `yaml:"servicePath"`
 
 	// 要生成的代码文件所在目录 必传
@@ -9,4 +29,68 @@ type Config struct {
 
 	// 模版文件地址
 	TemplateFile string `yaml:"templateFile"`
+
+	// 注解模式 默认为array 可使用任意通过annotation.RegisterAnnotationParser注册的模式
+	Mode annotation.AnnotationMode `yaml:"mode"`
+
+	// 模版执行范围 默认为file
+	Scope Scope `yaml:"scope"`
+
+	// 生成文件后缀 默认为_gen.go
+	FileSuffix string `yaml:"fileSuffix"`
+}
+
+// LoadConfig 加载yaml配置文件
+// 配置中的相对路径均相对于配置文件所在目录
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %s", err)
+	}
+	config := &Config{}
+	if err = yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %s", err)
+	}
+	baseDir := filepath.Dir(path)
+	config.SourcePath = resolvePath(baseDir, config.SourcePath)
+	config.GenFilePath = resolvePath(baseDir, config.GenFilePath)
+	config.TemplateFile = resolvePath(baseDir, config.TemplateFile)
+	return config, nil
+}
+
+func resolvePath(baseDir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// validate 校验配置并填充默认值
+func (c *Config) validate() error {
+	if c.SourcePath == "" {
+		return fmt.Errorf("servicePath is required")
+	}
+	if c.GenFilePath == "" {
+		return fmt.Errorf("genFilePath is required")
+	}
+	if c.TemplateFile == "" {
+		return fmt.Errorf("templateFile is required")
+	}
+	if c.Mode == "" {
+		c.Mode = annotation.AnnotationModeArray
+	}
+	if _, err := annotation.NewAnnotationParser(c.Mode); err != nil {
+		return err
+	}
+	switch c.Scope {
+	case "":
+		c.Scope = ScopeFile
+	case ScopeFile, ScopeStruct, ScopeInterface:
+	default:
+		return fmt.Errorf("unknown scope: %s", c.Scope)
+	}
+	if c.FileSuffix == "" {
+		c.FileSuffix = defaultFileSuffix
+	}
+	return nil
 }
@@ -0,0 +1,252 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	annotation "github.com/celt237/go-annotation"
+)
+
+// TemplateData 模版数据
+type TemplateData struct {
+	File      *annotation.FileDesc      // 文件信息
+	Struct    *annotation.StructDesc    // 结构体信息 范围为struct时有值
+	Interface *annotation.InterfaceDesc // 接口信息 范围为interface时有值
+}
+
+type Generator struct {
+	config   *Config
+	template *template.Template
+}
+
+// NewGenerator 创建代码生成器
+func NewGenerator(config *Config) (*Generator, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(config.TemplateFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %s", err)
+	}
+	tmpl, err := template.New(filepath.Base(config.TemplateFile)).Funcs(funcMap).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %s", err)
+	}
+	return &Generator{config: config, template: tmpl}, nil
+}
+
+// Generate 生成代码 返回生成的文件列表
+func (g *Generator) Generate() ([]string, error) {
+	filesDesc, err := g.loadFiles()
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(g.config.GenFilePath, 0755); err != nil {
+		return nil, err
+	}
+	genFiles := make([]string, 0)
+	written := make(map[string]string)
+	for _, fileDesc := range filesDesc {
+		for _, item := range g.split(fileDesc) {
+			genFile, err := g.execute(item, written)
+			if err != nil {
+				return nil, err
+			}
+			if genFile != "" {
+				genFiles = append(genFiles, genFile)
+			}
+		}
+	}
+	return genFiles, nil
+}
+
+// loadFiles 解析源码 生成的文件不作为源码 避免重复执行时读取上次的输出
+func (g *Generator) loadFiles() ([]*annotation.FileDesc, error) {
+	parser := annotation.NewParser(&annotation.Options{Mode: g.config.Mode, FileFilter: g.isSource})
+	if strings.HasSuffix(g.config.SourcePath, "...") {
+		pkgs, err := parser.GetPackagesDescList(g.config.SourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse source: %s", err)
+		}
+		filesDesc := make([]*annotation.FileDesc, 0)
+		for _, pkg := range pkgs {
+			filesDesc = append(filesDesc, pkg.Files...)
+		}
+		return filesDesc, nil
+	}
+	info, err := os.Stat(g.config.SourcePath)
+	if err != nil {
+		return nil, err
+	}
+	var filesDesc []*annotation.FileDesc
+	if info.IsDir() {
+		filesDesc, err = parser.GetFilesDescList(g.config.SourcePath)
+	} else if g.isSource(g.config.SourcePath) {
+		var fileDesc *annotation.FileDesc
+		fileDesc, err = parser.GetFileDesc(g.config.SourcePath)
+		filesDesc = []*annotation.FileDesc{fileDesc}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source: %s", err)
+	}
+	result := make([]*annotation.FileDesc, 0, len(filesDesc))
+	for _, fileDesc := range filesDesc {
+		if fileDesc != nil {
+			result = append(result, fileDesc)
+		}
+	}
+	return result, nil
+}
+
+// isSource 文件是否为源码 以生成文件后缀结尾的文件及源码目录内的输出目录中的文件视为生成的文件
+// 输出目录为源码目录本身时 如 //go:generate 仅按后缀判断
+func (g *Generator) isSource(path string) bool {
+	if strings.HasSuffix(filepath.Base(path), g.config.FileSuffix) {
+		return false
+	}
+	genDir, err := filepath.Abs(g.config.GenFilePath)
+	if err != nil {
+		return true
+	}
+	sourceDir, err := filepath.Abs(strings.TrimSuffix(g.config.SourcePath, "..."))
+	if err != nil {
+		return true
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return true
+	}
+	return !isWithin(absPath, genDir) || isWithin(sourceDir, genDir)
+}
+
+// isWithin path是否为dir或其中的文件、子目录
+func isWithin(path string, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// hasContent 文件是否带有注解或带有注解的声明 无内容的文件在文件范围下不生成代码
+func hasContent(fileDesc *annotation.FileDesc) bool {
+	if len(fileDesc.Annotations) > 0 || len(fileDesc.Funcs) > 0 || len(fileDesc.NamedTypes) > 0 || len(fileDesc.Consts) > 0 || len(fileDesc.Vars) > 0 {
+		return true
+	}
+	for _, structDesc := range fileDesc.Structs {
+		if structDesc != nil {
+			return true
+		}
+	}
+	for _, interfaceDesc := range fileDesc.Interfaces {
+		if interfaceDesc != nil {
+			return true
+		}
+	}
+	return false
+}
+
+type templateItem struct {
+	name   string // 生成文件名 不含后缀
+	source string // 来源 如 完整包名/文件名 用于提示生成文件重名
+	data   *TemplateData
+}
+
+// split 按执行范围拆分模版数据
+func (g *Generator) split(fileDesc *annotation.FileDesc) []*templateItem {
+	items := make([]*templateItem, 0)
+	source := fileDesc.FileName
+	if fileDesc.FullPackageName != "" {
+		source = fileDesc.FullPackageName + "/" + fileDesc.FileName
+	}
+	switch g.config.Scope {
+	case ScopeStruct:
+		for _, structDesc := range fileDesc.Structs {
+			if structDesc != nil {
+				items = append(items, &templateItem{name: toSnakeCase(structDesc.Name), source: source + " " + structDesc.Name, data: &TemplateData{File: fileDesc, Struct: structDesc}})
+			}
+		}
+	case ScopeInterface:
+		for _, interfaceDesc := range fileDesc.Interfaces {
+			if interfaceDesc != nil {
+				items = append(items, &templateItem{name: toSnakeCase(interfaceDesc.Name), source: source + " " + interfaceDesc.Name, data: &TemplateData{File: fileDesc, Interface: interfaceDesc}})
+			}
+		}
+	default:
+		if hasContent(fileDesc) {
+			items = append(items, &templateItem{name: strings.TrimSuffix(fileDesc.FileName, ".go"), source: source, data: &TemplateData{File: fileDesc}})
+		}
+	}
+	return items
+}
+
+// execute 执行模版并将格式化后的代码写入文件 模版输出为空时不生成文件
+// written: 已生成的文件 -> 来源 不同目录下的同名文件或同名类型会生成同一文件 此时返回错误 不覆盖已生成的文件
+func (g *Generator) execute(item *templateItem, written map[string]string) (string, error) {
+	name := item.name
+	var buf bytes.Buffer
+	if err := g.template.Execute(&buf, item.data); err != nil {
+		return "", fmt.Errorf("failed to execute template for %s: %s", name, err)
+	}
+	if len(bytes.TrimSpace(buf.Bytes())) == 0 {
+		return "", nil
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code for %s: %s", name, err)
+	}
+	genFile := filepath.Join(g.config.GenFilePath, name+g.config.FileSuffix)
+	if source, ok := written[genFile]; ok {
+		return "", fmt.Errorf("%s and %s both generate %s", source, item.source, genFile)
+	}
+	written[genFile] = item.source
+	if err = os.WriteFile(genFile, code, 0644); err != nil {
+		return "", err
+	}
+	return genFile, nil
+}
+
+var funcMap = template.FuncMap{
+	"lowerFirst": lowerFirst,
+	"upperFirst": upperFirst,
+	"snakeCase":  toSnakeCase,
+	"join":       strings.Join,
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// toSnakeCase 驼峰转下划线 如 UserService -> user_service
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				builder.WriteByte('_')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,182 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTemplate = `package {{.File.PackageName}}
+
+{{with .Struct}}// {{lowerFirst .Name}}Annotations {{.Description}}
+var {{lowerFirst .Name}}Annotations = map[string]int{
+{{range .Methods}}	"{{.Name}}": {{len .Annotations}},
+{{end}}}
+{{end}}`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "service.tmpl"), []byte(testTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := filepath.Abs("../test/data/mapmode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := "servicePath: " + source + "\n" +
+		"genFilePath: gen\n" +
+		"templateFile: service.tmpl\n" +
+		"mode: map\n" +
+		"scope: struct\n"
+	configFile := filepath.Join(dir, "config.yaml")
+	if err = os.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if c.GenFilePath != filepath.Join(dir, "gen") {
+		t.Errorf("LoadConfig() GenFilePath = %s, want %s", c.GenFilePath, filepath.Join(dir, "gen"))
+	}
+	generator, err := NewGenerator(c)
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	genFiles, err := generator.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	wantFiles := []string{
+		filepath.Join(dir, "gen", "struct_two_gen.go"),
+		filepath.Join(dir, "gen", "struct_one_gen.go"),
+	}
+	if strings.Join(genFiles, ",") != strings.Join(wantFiles, ",") {
+		t.Fatalf("Generate() files = %v, want %v", genFiles, wantFiles)
+	}
+	code, err := os.ReadFile(wantFiles[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "var structOneAnnotations = map[string]int{\n\t\"Method1\": 1,"
+	if !strings.Contains(string(code), want) {
+		t.Errorf("generated code does not contain %q:\n%s", want, code)
+	}
+}
+
+func TestNewGeneratorInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{
+			name:   "缺少源码目录",
+			config: &Config{GenFilePath: "gen", TemplateFile: "a.tmpl"},
+		},
+		{
+			name:   "缺少模版",
+			config: &Config{SourcePath: ".", GenFilePath: "gen"},
+		},
+		{
+			name:   "未知范围",
+			config: &Config{SourcePath: ".", GenFilePath: "gen", TemplateFile: "a.tmpl", Scope: "package"},
+		},
+		{
+			name:   "未注册的注解模式",
+			config: &Config{SourcePath: ".", GenFilePath: "gen", TemplateFile: "a.tmpl", Mode: "unknown"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGenerator(tt.config); err == nil {
+				t.Errorf("NewGenerator() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestGenerateNameCollision(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module collision\n",
+		"a/service.go": "package a\n\n// Service a\n// @service\ntype Service struct{}\n",
+		"b/service.go": "package b\n\n// Service b\n// @service\ntype Service struct{}\n",
+		"service.tmpl": "package gen\n\n// {{.File.FullPackageName}}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 不同目录下的同名文件会生成同一文件 返回错误而不是覆盖
+	generator, err := NewGenerator(&Config{SourcePath: dir, GenFilePath: filepath.Join(dir, "gen"), TemplateFile: filepath.Join(dir, "service.tmpl")})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	if _, err = generator.Generate(); err == nil || !strings.Contains(err.Error(), "both generate") {
+		t.Fatalf("Generate() error = %v, want name collision error", err)
+	}
+	code, err := os.ReadFile(filepath.Join(dir, "gen", "service_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), "collision/a") {
+		t.Errorf("generated code = %s, want the first file kept", code)
+	}
+}
+
+func TestGenerateSkipsOutput(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module skip\n",
+		"svc/svc.go":   "package svc\n\n// Service 服务\n// @service\ntype Service struct{}\n",
+		"svc/plain.go": "package svc\n\nimport \"fmt\"\n\nfunc plain() { fmt.Println() }\n",
+		"service.tmpl": "package {{.File.PackageName}}\n\nimport \"fmt\"\n\n// {{.File.FileName}}\nvar _ = fmt.Sprint()\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name    string
+		genPath string
+	}{
+		{name: "输出到源码目录", genPath: "svc"},
+		{name: "输出到源码目录的子目录", genPath: "svc/gen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genDir := filepath.Join(dir, tt.genPath)
+			config := &Config{SourcePath: filepath.Join(dir, "svc"), GenFilePath: genDir, TemplateFile: filepath.Join(dir, "service.tmpl")}
+			// 重复生成时不读取上次生成的文件 无注解的文件不生成代码
+			for i := 0; i < 2; i++ {
+				generator, err := NewGenerator(config)
+				if err != nil {
+					t.Fatalf("NewGenerator() error = %v", err)
+				}
+				genFiles, err := generator.Generate()
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if len(genFiles) != 1 || filepath.Base(genFiles[0]) != "svc_gen.go" {
+					t.Fatalf("Generate() files = %v, want svc_gen.go", genFiles)
+				}
+			}
+			matches, _ := filepath.Glob(filepath.Join(genDir, "*_gen_gen.go"))
+			if _, err := os.Stat(filepath.Join(genDir, "plain_gen.go")); err == nil || len(matches) > 0 {
+				t.Errorf("generated files = %v, want only svc_gen.go", matches)
+			}
+		})
+	}
+}
@@ -1,28 +1,104 @@
 package go_annotation
 
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+)
+
+// Parser 注解解析器 解析选项在创建时确定 可在多个goroutine中并发使用
+type Parser struct {
+	options *Options
+}
+
+// NewParser 创建解析器
+// options: 解析选项 为nil时使用默认选项
+func NewParser(options *Options) *Parser {
+	return &Parser{options: options.withDefaults()}
+}
+
 // GetFileDesc 获取文件描述
 // fileName: 文件名
-func GetFileDesc(fileName string, mode AnnotationMode) (*FileDesc, error) {
-	currentAnnotationMode = mode
-	return GetFileParser(fileName).Parse()
+func (p *Parser) GetFileDesc(fileName string) (*FileDesc, error) {
+	return NewFileParser(fileName, p.options).Parse()
 }
 
 // GetFilesDescList 获取文件描述列表
 // directory: 目录
-func GetFilesDescList(directory string, mode AnnotationMode) ([]*FileDesc, error) {
-	currentAnnotationMode = mode
+// 单个文件解析失败不会中断 所有文件的诊断信息以ErrorList返回 同时返回已解析的文件
+func (p *Parser) GetFilesDescList(directory string) ([]*FileDesc, error) {
+	if p.options.err != nil {
+		return nil, p.options.err
+	}
 	var filesDesc []*FileDesc
+	var diagnostics ErrorList
 	// 读取目录下的所有文件
 	fileNames, err := GetFileNames(directory)
 	if err != nil {
 		return nil, err
 	}
+	// 同一目录下的文件只解析一次
+	dirs := make(map[string]*parsedDir)
 	for _, fileName := range fileNames {
-		fileDesc, err := GetFileParser(fileName).Parse()
+		if !p.options.acceptFile(fileName) {
+			continue
+		}
+		path := filepath.Clean(fileName)
+		dir, ok := dirs[filepath.Dir(path)]
+		if !ok {
+			dir = &parsedDir{fset: token.NewFileSet()}
+			dir.files, dir.errs = parseDirFiles(dir.fset, filepath.Dir(path), "")
+			dir.checker = newDirChecker(dir.fset, filepath.Dir(path), p.options)
+			dirs[filepath.Dir(path)] = dir
+		}
+		if err, ok := dir.errs[path]; ok {
+			diagnostics = append(diagnostics, toDiagnostics(err, path)...)
+			continue
+		}
+		fileParser := NewFileParser(fileName, p.options)
+		if file, ok := dir.files[path]; ok {
+			fileParser.fset = dir.fset
+			fileParser.file = file
+			fileParser.packageFiles = samePackageFiles(dir.files, path)
+			fileParser.checker = dir.checker
+		}
+		fileDesc, err := fileParser.Parse()
 		if err != nil {
-			return nil, err
+			diagnostics = append(diagnostics, toDiagnostics(err, path)...)
 		}
 		filesDesc = append(filesDesc, fileDesc)
 	}
-	return filesDesc, nil
+	diagnostics.Sort()
+	return filesDesc, diagnostics.Err()
+}
+
+type parsedDir struct {
+	fset    *token.FileSet
+	files   map[string]*ast.File
+	errs    map[string]error
+	checker *dirChecker
+}
+
+// GetPackagesDescList 按包加载并解析 包内所有文件只加载和类型检查一次
+// patterns: 包匹配模式 如 ./...
+func (p *Parser) GetPackagesDescList(patterns ...string) ([]*PackageDesc, error) {
+	return NewPackageParser(patterns, p.options).Parse()
+}
+
+// GetFileDesc 获取文件描述
+// fileName: 文件名
+func GetFileDesc(fileName string, mode AnnotationMode) (*FileDesc, error) {
+	return NewParser(&Options{Mode: mode}).GetFileDesc(fileName)
+}
+
+// GetFilesDescList 获取文件描述列表
+// directory: 目录
+func GetFilesDescList(directory string, mode AnnotationMode) ([]*FileDesc, error) {
+	return NewParser(&Options{Mode: mode}).GetFilesDescList(directory)
+}
+
+// GetPackagesDescList 获取包描述列表
+// patterns: 包匹配模式 如 ./...
+func GetPackagesDescList(mode AnnotationMode, patterns ...string) ([]*PackageDesc, error) {
+	return NewParser(&Options{Mode: mode}).GetPackagesDescList(patterns...)
 }
@@ -1,4 +1,8 @@
 module github.com/celt237/go-annotation
 
-go 1.16
+go 1.22.0
 
+require (
+	golang.org/x/mod v0.20.0
+	gopkg.in/yaml.v3 v3.0.1
+)
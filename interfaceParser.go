@@ -11,64 +11,121 @@ type InterfaceParser struct {
 	interfaceSpec *ast.InterfaceType
 	serviceName   string
 	fileImports   map[string]*ImportDesc
+	options       *Options
+	resolver      *typeResolver
+	diagnostics   *diagnosticCollector
+
+	comments     []*ast.CommentGroup // 接口所在文件的注释 用于解析参数上的注释
+	packageFiles []*ast.File         // 同包的所有文件 用于查找嵌入的接口
+	embeds       *embedResolver      // 嵌入类型声明查找 为空时仅查找同包的文件
 }
 
 func NewInterfaceParser(
 	serviceName string,
 	typeSpec *ast.TypeSpec,
 	genDecl *ast.GenDecl,
-	fileImports map[string]*ImportDesc) *InterfaceParser {
+	fileImports map[string]*ImportDesc,
+	options *Options) *InterfaceParser {
 	return &InterfaceParser{
 		serviceName:   serviceName,
 		typeSpec:      typeSpec,
 		genDecl:       genDecl,
 		interfaceSpec: typeSpec.Type.(*ast.InterfaceType),
 		fileImports:   fileImports,
+		options:       options.withDefaults(),
+		resolver:      &typeResolver{imports: fileImports},
+		diagnostics:   newDiagnosticCollector(nil, ""),
 	}
 }
 
 func (s *InterfaceParser) Parse() (*InterfaceDesc, error) {
-	comments := parseAtComments(s.genDecl.Doc)
+	if s.options.err != nil {
+		return nil, s.options.err
+	}
+	comments, annotations, occurrences := parseAnnotations(s.serviceName, s.options, s.diagnostics, s.genDecl.Doc)
 	description := parseDescription(s.serviceName, s.genDecl.Doc)
 	funcList, err := s.getFuncList()
 	if err != nil {
 		return nil, err
 	}
-	if funcList == nil || len(funcList) == 0 {
+	imports := make(map[string]*ImportDesc)
+	embeds, promoted := s.parserEmbeds(imports)
+	if len(funcList) == 0 && len(embeds) == 0 {
 		return nil, nil
 	}
 	methods := make([]*MethodDesc, 0)
 	for _, method := range funcList {
 		methodDesc, err := s.parserMethod(method)
 		if err != nil {
-			return nil, err
+			// 无法解析的方法记录诊断信息后跳过 不影响其他方法
+			s.diagnostics.add(method.Pos(), s.serviceName, "%s", err)
+			continue
 		}
 		methods = append(methods, methodDesc)
 	}
+	for name, imp := range s.parserImports(methods, embeds) {
+		imports[name] = imp
+	}
+	methods = appendPromoted(methods, promoted)
 	sDesc := &InterfaceDesc{
 		Name:        s.serviceName,
 		Description: description,
+		Embeds:      embeds,
 		Methods:     methods,
-		Imports:     s.parserImports(methods),
+		Imports:     imports,
 		Comments:    comments,
-		Annotations: getAnnotationParser(currentAnnotationMode).Parse(comments),
+		Annotations: annotations,
+		Occurrences: occurrences,
+		TypeParams:  parseTypeParams(s.typeSpec.TypeParams),
 	}
 	return sDesc, nil
 }
 
+// getFuncList 获取接口的方法 不含嵌入的接口及类型约束
 func (s *InterfaceParser) getFuncList() ([]*ast.Field, error) {
 	list := make([]*ast.Field, 0)
 	for _, method := range s.interfaceSpec.Methods.List {
-		list = append(list, method)
+		if len(method.Names) > 0 {
+			list = append(list, method)
+		}
 	}
 	return list, nil
 }
 
+// parserEmbeds 解析嵌入的接口 开启FlattenEmbeds时同时返回嵌入接口的方法
+// 类型约束 如 ~int | ~string 不视为嵌入
+func (s *InterfaceParser) parserEmbeds(imports map[string]*ImportDesc) ([]*EmbedDesc, []*MethodDesc) {
+	embeds := make([]*EmbedDesc, 0)
+	var promoted []*MethodDesc
+	resolver := s.embeds
+	if resolver == nil {
+		resolver = newEmbedResolver(nil, "", s.packageFiles, s.resolver.packagePath)
+	}
+	for _, method := range s.interfaceSpec.Methods.List {
+		if len(method.Names) > 0 {
+			continue
+		}
+		switch embeddedType(method.Type).(type) {
+		case *ast.Ident, *ast.SelectorExpr:
+		default:
+			continue
+		}
+		decl := resolver.lookup(method.Type, s.fileImports, resolver.packageFiles, s.resolver.packagePath)
+		embed := parseEmbed(method.Type, s.resolver, decl, s.options)
+		embeds = append(embeds, embed)
+		if s.options.FlattenEmbeds {
+			promoted = append(promoted, resolver.promotedMethods(embed, decl, s.options, imports)...)
+		}
+	}
+	return embeds, promoted
+}
+
 func (s *InterfaceParser) parserMethod(method *ast.Field) (methodDesc *MethodDesc, err error) {
 	methodDesc = &MethodDesc{
-		Comments: make([]string, 0),
-		Params:   make([]*Field, 0),
-		Results:  make([]*Field, 0),
+		Comments:   make([]string, 0),
+		TypeParams: make([]*TypeParam, 0),
+		Params:     make([]*Field, 0),
+		Results:    make([]*Field, 0),
 	}
 	// method name
 	if method.Names == nil || len(method.Names) == 0 {
@@ -82,30 +139,17 @@ func (s *InterfaceParser) parserMethod(method *ast.Field) (methodDesc *MethodDes
 	methodDesc.Name = method.Names[0].Name
 	// funcType
 	if funcType, ok := method.Type.(*ast.FuncType); ok {
-		// params
-		if funcType.Params != nil {
-			for _, param := range funcType.Params.List {
-				field, err := parseField(param)
-				if err != nil {
-					return nil, err
-				}
-				methodDesc.Params = append(methodDesc.Params, field)
-			}
-		}
-		// results
-		if funcType.Results != nil {
-			for _, result := range funcType.Results.List {
-				field, err := parseField(result)
-				if err != nil {
-					return nil, err
-				}
-				methodDesc.Results = append(methodDesc.Results, field)
-			}
+		// params and results
+		resolver := s.resolver.withTypeParams(parseTypeParams(s.typeSpec.TypeParams))
+		if methodDesc.Params, methodDesc.Results, err = parseSignature(funcType, resolver); err != nil {
+			return nil, err
 		}
 		// comment
-		methodDesc.Comments = parseAtComments(method.Doc)
+		target := s.serviceName + "." + methodDesc.Name
+		methodDesc.Comments, methodDesc.Annotations, methodDesc.Occurrences = parseAnnotations(target, s.options, s.diagnostics, method.Doc)
+		parseParamComments(target, funcType.Params, methodDesc.Params, s.comments, s.options, s.diagnostics)
+		bindParamAnnotations(target, methodDesc.Occurrences, methodDesc.Params, s.options, s.diagnostics)
 		methodDesc.Description = parseDescription(methodDesc.Name, method.Doc)
-		methodDesc.Annotations = getAnnotationParser(currentAnnotationMode).Parse(methodDesc.Comments)
 		return methodDesc, err
 	} else {
 		err = fmt.Errorf("method type is not funcType")
@@ -113,7 +157,8 @@ func (s *InterfaceParser) parserMethod(method *ast.Field) (methodDesc *MethodDes
 	}
 }
 
-func (s *InterfaceParser) parserImports(methods []*MethodDesc) (imports map[string]*ImportDesc) {
+// parserImports 获取方法参数、返回值及嵌入类型用到的导入
+func (s *InterfaceParser) parserImports(methods []*MethodDesc, embeds []*EmbedDesc) (imports map[string]*ImportDesc) {
 	imports = make(map[string]*ImportDesc)
 	fields := make([]*Field, 0)
 	for _, method := range methods {
@@ -125,9 +170,13 @@ func (s *InterfaceParser) parserImports(methods []*MethodDesc) (imports map[stri
 		}
 	}
 	for _, field := range fields {
-		if imp, ok := s.fileImports[field.PackageName]; ok {
-			imports[field.PackageName] = imp
+		addFieldImports(imports, s.fileImports, field)
+	}
+	for _, embed := range embeds {
+		if imp, ok := s.fileImports[embed.PackageName]; ok {
+			imports[embed.PackageName] = imp
 		}
+		addTypeImports(imports, s.fileImports, embed.Type)
 	}
 	return imports
 }
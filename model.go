@@ -1,16 +1,60 @@
 package go_annotation
 
-type AnnotationMode string // 注解模式
+import (
+	"go/token"
+	"go/types"
+	"time"
+)
+
+type AnnotationMode string // 注解模式 内置array、array-named、map、swag 可通过RegisterAnnotationParser注册自定义模式
 
 const (
-	AnnotationModeArray AnnotationMode = "array" // 数组注解模式
-	AnnotationModeMap   AnnotationMode = "map"   // map注解模式
+	AnnotationModeArray      AnnotationMode = "array"       // 数组注解模式
+	AnnotationModeArrayNamed AnnotationMode = "array-named" // 数组注解模式 key=value 形式的参数同时作为命名属性
+	AnnotationModeMap        AnnotationMode = "map"         // map注解模式
+	AnnotationModeSwag       AnnotationMode = "swag"        // swaggo风格注解模式
 )
 
 // Annotation 注解
 type Annotation struct {
-	Name       string              // 注解名称
-	Attributes []map[string]string // 注解属性
+	Name       string                        // 注解名称
+	Attributes []map[string]string           // 注解属性
+	Values     []map[string]*AnnotationValue `json:",omitempty"` // 注解属性值 与Attributes一一对应 仅map及swag模式有值
+}
+
+type AnnotationValueKind string // 注解属性值类型
+
+const (
+	AnnotationValueString     AnnotationValueKind = "string"     // 字符串 "a" 或 `a`
+	AnnotationValueInt        AnnotationValueKind = "int"        // 整数 如 10、0x1f、-1
+	AnnotationValueFloat      AnnotationValueKind = "float"      // 浮点数 如 1.5
+	AnnotationValueBool       AnnotationValueKind = "bool"       // 布尔值 true、false
+	AnnotationValueDuration   AnnotationValueKind = "duration"   // 时间间隔 如 10s、1h30m
+	AnnotationValueList       AnnotationValueKind = "list"       // 列表 如 ["a", "b"]
+	AnnotationValueAnnotation AnnotationValueKind = "annotation" // 嵌套注解 如 @Header(name="x")
+	AnnotationValueIdent      AnnotationValueKind = "ident"      // 其他未加引号的值 如 GET、pkg.Const
+)
+
+// AnnotationValue 注解属性值 根据Kind读取对应字段
+type AnnotationValue struct {
+	Kind       AnnotationValueKind // 类型
+	Raw        string              // 原始文本
+	String     string              `json:",omitempty"` // 字符串及标识符的值
+	Int        int64               `json:",omitempty"`
+	Float      float64             `json:",omitempty"`
+	Bool       bool                `json:",omitempty"`
+	Duration   time.Duration       `json:",omitempty"`
+	List       []*AnnotationValue  `json:",omitempty"`
+	Annotation *Annotation         `json:",omitempty"`
+}
+
+// AnnotationOccurrence 注解的一次出现
+type AnnotationOccurrence struct {
+	Name       string                      // 注解名称
+	Raw        string                      // 原始文本 不含注解前缀
+	Position   token.Position              // 位置
+	Attributes map[string]string           `json:",omitempty"` // 本次出现的属性
+	Values     map[string]*AnnotationValue `json:",omitempty"` // 本次出现的属性值 仅map及swag模式有值
 }
 
 // FileDesc  文件信息
@@ -19,9 +63,34 @@ type FileDesc struct {
 	FullPackageName string // 完整包名
 	FileName        string // 文件名
 	//RelativePath string // todo 相对路径
-	Imports    map[string]*ImportDesc
-	Structs    []*StructDesc
-	Interfaces []*InterfaceDesc
+	Comments    []string                // 文件级注释 package子句之前的所有注释中的注解 含包注释
+	Annotations map[string]*Annotation  // 文件级注解
+	Occurrences []*AnnotationOccurrence // 按声明顺序排列的文件级注解
+	Imports     map[string]*ImportDesc
+	Structs     []*StructDesc
+	Interfaces  []*InterfaceDesc
+	Funcs       []*FuncDesc
+	NamedTypes  []*NamedTypeDesc  // 带有注解的具名类型 不含结构体及接口
+	Consts      []*ValueGroupDesc // 带有注解的常量组
+	Vars        []*ValueGroupDesc // 带有注解的变量组
+
+	Types     *types.Package `json:"-"` // 类型检查后的包信息 仅按包加载时有值
+	TypesInfo *types.Info    `json:"-"` // 类型检查信息 仅按包加载时有值
+}
+
+// PackageDesc  包信息
+type PackageDesc struct {
+	Name    string      // 包名
+	PkgPath string      // 完整包名
+	Dir     string      // 包所在目录
+	Files   []*FileDesc // 文件信息
+
+	Comments    []string                // 包注释中的注解 合并包内所有文件紧邻package子句的注释 如doc.go
+	Annotations map[string]*Annotation  // 包级注解
+	Occurrences []*AnnotationOccurrence // 按文件及声明顺序排列的包级注解
+
+	Types     *types.Package `json:"-"` // 类型检查后的包信息
+	TypesInfo *types.Info    `json:"-"` // 类型检查信息
 }
 
 // ImportDesc  import信息
@@ -33,40 +102,171 @@ type ImportDesc struct {
 
 // StructDesc  结构体信息
 type StructDesc struct {
-	Name        string                 // 结构体名
-	Imports     map[string]*ImportDesc // 导入信息
-	Comments    []string               // 注释
-	Annotations map[string]*Annotation // 注解
-	//Fields      []*Field               // 字段 暂不支持
-	Methods     []*MethodDesc // 方法
-	Description string        // 描述
+	Name        string                  // 结构体名
+	Imports     map[string]*ImportDesc  // 导入信息
+	Comments    []string                // 注释
+	Annotations map[string]*Annotation  // 注解
+	Occurrences []*AnnotationOccurrence // 按声明顺序排列的注解
+	TypeParams  []*TypeParam            // 类型参数
+	Fields      []*Field                // 字段
+	Embeds      []*EmbedDesc            // 嵌入类型 对应的嵌入字段同时在Fields中
+	Methods     []*MethodDesc           // 方法
+	Description string                  // 描述
 }
 
 // InterfaceDesc  接口信息
 type InterfaceDesc struct {
-	Name        string                 // 接口名
-	Imports     map[string]*ImportDesc // 导入信息
-	Comments    []string               // 注释
-	Annotations map[string]*Annotation // 注解
-	Methods     []*MethodDesc          // 方法
-	Description string                 // 描述
+	Name        string                  // 接口名
+	Imports     map[string]*ImportDesc  // 导入信息
+	Comments    []string                // 注释
+	Annotations map[string]*Annotation  // 注解
+	Occurrences []*AnnotationOccurrence // 按声明顺序排列的注解
+	TypeParams  []*TypeParam            // 类型参数
+	Embeds      []*EmbedDesc            // 嵌入的接口
+	Methods     []*MethodDesc           // 方法
+	Description string                  // 描述
 }
 
+// EmbedDesc  嵌入类型信息
+type EmbedDesc struct {
+	Name        string    // 嵌入类型名 不含指针及类型实参 如 io.Reader、Base
+	DataType    string    // 类型 如 *Base、pkg.List[int]
+	PackageName string    // 包名
+	IsPtr       bool      // 是否是指针
+	Type        *TypeDesc // 解析后的类型信息
+	Resolved    bool      // 是否找到了嵌入类型的声明 仅查找当前包及当前模块内的包
+
+	// 以下为嵌入类型声明上的注释及注解 仅Resolved为true时有值
+	Description string                 `json:",omitempty"` // 描述
+	Comments    []string               `json:",omitempty"` // 注释
+	Annotations map[string]*Annotation `json:",omitempty"` // 注解
+}
+
+type ReceiverKind string // 方法接收者种类
+
+const (
+	ReceiverKindPointer ReceiverKind = "pointer" // 指针接收者 *T
+	ReceiverKindValue   ReceiverKind = "value"   // 值接收者 T
+)
+
 // MethodDesc  方法信息
 type MethodDesc struct {
-	Name        string                 // 方法名
-	Description string                 // 描述
-	Comments    []string               // 注释
-	Annotations map[string]*Annotation // 注解
-	Params      []*Field               // 参数
-	Results     []*Field               // 返回值
+	Name         string                  // 方法名
+	ReceiverName string                  // 接收者变量名 仅结构体方法有值
+	ReceiverKind ReceiverKind            // 接收者种类 仅结构体方法有值
+	Description  string                  // 描述
+	Comments     []string                // 注释
+	Annotations  map[string]*Annotation  // 注解
+	Occurrences  []*AnnotationOccurrence // 按声明顺序排列的注解
+	TypeParams   []*TypeParam            // 接收者的类型参数 如 func (l *List[T]) 中的T 约束取自结构体声明 仅结构体方法有值
+	Params       []*Field                // 参数
+	Results      []*Field                // 返回值
+	PromotedFrom string                  `json:",omitempty"` // 提升方法所属的嵌入类型 仅开启Options.FlattenEmbeds时有值
+}
+
+// FuncDesc  函数信息 仅包含包级函数 不含方法
+type FuncDesc struct {
+	Name        string                  // 函数名
+	Imports     map[string]*ImportDesc  // 导入信息
+	Description string                  // 描述
+	Comments    []string                // 注释
+	Annotations map[string]*Annotation  // 注解
+	Occurrences []*AnnotationOccurrence // 按声明顺序排列的注解
+	TypeParams  []*TypeParam            // 类型参数
+	Params      []*Field                // 参数
+	Results     []*Field                // 返回值
+}
+
+// NamedTypeDesc  具名类型信息 如 type Status int、type Handler func()、type ID = string
+type NamedTypeDesc struct {
+	Name        string                  // 类型名
+	Imports     map[string]*ImportDesc  // 导入信息
+	Description string                  // 描述
+	Comments    []string                // 注释
+	Annotations map[string]*Annotation  // 注解
+	Occurrences []*AnnotationOccurrence // 按声明顺序排列的注解
+	TypeParams  []*TypeParam            // 类型参数
+	DataType    string                  // 声明的类型 如 int、map[string]int 类型别名为其指向的类型
+	Type        *TypeDesc               // 解析后的类型信息
+	IsAlias     bool                    // 是否是类型别名
+	Values      []*ValueDesc            // 同包中该类型的常量 按声明顺序 可用于生成枚举方法
+}
+
+// ValueGroupDesc  常量组或变量组信息 未分组的声明视为只有一个值的组
+type ValueGroupDesc struct {
+	IsConst     bool                    // 是否是常量组
+	Description string                  // 描述 仅分组声明有值
+	Comments    []string                // 注释 仅分组声明有值 未分组声明的注释属于其中的值
+	Annotations map[string]*Annotation  // 注解
+	Occurrences []*AnnotationOccurrence // 按声明顺序排列的注解
+	Values      []*ValueDesc            // 值 按声明顺序
+}
+
+// ValueDesc  常量或变量信息
+type ValueDesc struct {
+	Name        string                  // 名称
+	DataType    string                  // 类型 常量组中省略类型时沿用上一行 无类型常量为空
+	Value       string                  // 值 常量为计算后的值 如 1、"a" 无法计算时及变量为初始化表达式
+	Iota        int                     // 在常量组中的序号 即iota的值 变量为0
+	Description string                  // 描述
+	Comments    []string                // 注释
+	Annotations map[string]*Annotation  // 注解
+	Occurrences []*AnnotationOccurrence // 按声明顺序排列的注解
 }
 
-// Field  字段信息（入参、出参）
+// TypeParam  类型参数
+type TypeParam struct {
+	Name       string // 参数名
+	Constraint string // 约束 如 any、~int | ~string
+}
+
+// Field  字段信息（入参、出参、结构体字段）
 type Field struct {
-	Name         string //  字段名
-	DataType     string // 字段类型
-	PackageName  string // 包名
-	RealDataType string // 真实类型 不含指针
-	IsPtr        bool   // 是否是指针
+	Name         string    //  字段名
+	DataType     string    // 字段类型
+	PackageName  string    // 包名
+	RealDataType string    // 真实类型 不含指针
+	IsPtr        bool      // 是否是指针
+	Type         *TypeDesc // 解析后的类型信息
+
+	// 以下仅参数有值
+	IsVariadic bool `json:",omitempty"` // 是否是可变参数 类型为 ...T
+
+	// 以下仅结构体字段有值 Comments、Annotations、Occurrences参数也有值
+	IsEmbedded  bool                    `json:",omitempty"` // 是否是嵌入字段 嵌入字段的字段名为类型名
+	Tag         string                  `json:",omitempty"` // 原始标签 不含反引号
+	Tags        map[string]string       `json:",omitempty"` // 解析后的标签 如 json:"id,omitempty" 解析为 json -> id,omitempty
+	Comments    []string                `json:",omitempty"` // 注释
+	Annotations map[string]*Annotation  `json:",omitempty"` // 注解
+	Occurrences []*AnnotationOccurrence `json:",omitempty"` // 按声明顺序排列的注解
+	Description string                  `json:",omitempty"` // 描述
+}
+
+type TypeKind string // 类型种类
+
+const (
+	TypeKindBasic     TypeKind = "basic"     // 基础类型 如 int string
+	TypeKindNamed     TypeKind = "named"     // 具名类型 如 data.A1 error
+	TypeKindPointer   TypeKind = "pointer"   // 指针
+	TypeKindSlice     TypeKind = "slice"     // 切片
+	TypeKindArray     TypeKind = "array"     // 数组
+	TypeKindMap       TypeKind = "map"       // map
+	TypeKindChan      TypeKind = "chan"      // chan
+	TypeKindFunc      TypeKind = "func"      // 函数
+	TypeKindStruct    TypeKind = "struct"    // 匿名结构体
+	TypeKindInterface TypeKind = "interface" // 匿名接口
+	TypeKindTypeParam TypeKind = "typeParam" // 泛型类型参数
+)
+
+// TypeDesc  类型信息
+type TypeDesc struct {
+	Kind        TypeKind    // 类型种类
+	ImportPath  string      `json:",omitempty"` // 导入路径 仅具名类型有值 内置类型为空
+	PackageName string      `json:",omitempty"` // 包名 为包声明的名称而非导入别名
+	TypeName    string      `json:",omitempty"` // 类型名 不含包名 仅基础类型、具名类型及类型参数有值
+	Elem        *TypeDesc   `json:",omitempty"` // 元素类型 指针、切片、数组、map、chan
+	Key         *TypeDesc   `json:",omitempty"` // 键类型 仅map
+	TypeArgs    []*TypeDesc `json:",omitempty"` // 泛型实参
+	Params      []*TypeDesc `json:",omitempty"` // 参数类型 仅函数 可变参数为切片
+	Results     []*TypeDesc `json:",omitempty"` // 返回值类型 仅函数
 }
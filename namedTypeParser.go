@@ -0,0 +1,74 @@
+package go_annotation
+
+import (
+	"go/ast"
+)
+
+type NamedTypeParser struct {
+	typeSpec    *ast.TypeSpec
+	genDecl     *ast.GenDecl
+	fileImports map[string]*ImportDesc
+	options     *Options
+	resolver    *typeResolver
+	diagnostics *diagnosticCollector
+
+	consts func() []*ValueDesc // 同包的所有常量 用于收集枚举值 为空时不收集
+}
+
+// NewNamedTypeParser 创建具名类型解析器 用于结构体及接口以外的类型声明及类型别名
+func NewNamedTypeParser(typeSpec *ast.TypeSpec,
+	genDecl *ast.GenDecl,
+	fileImports map[string]*ImportDesc,
+	options *Options) *NamedTypeParser {
+	return &NamedTypeParser{
+		typeSpec:    typeSpec,
+		genDecl:     genDecl,
+		fileImports: fileImports,
+		options:     options.withDefaults(),
+		resolver:    &typeResolver{imports: fileImports},
+		diagnostics: newDiagnosticCollector(nil, ""),
+	}
+}
+
+// Parse 解析具名类型 不含注解的类型返回nil
+func (s *NamedTypeParser) Parse() (*NamedTypeDesc, error) {
+	if s.options.err != nil {
+		return nil, s.options.err
+	}
+	name := s.typeSpec.Name.Name
+	doc := s.typeSpec.Doc
+	if doc == nil {
+		doc = s.genDecl.Doc
+	}
+	comments, annotations, occurrences := parseAnnotations(name, s.options, s.diagnostics, doc)
+	if len(comments) == 0 {
+		return nil, nil
+	}
+	resolver := s.resolver.withTypeParams(parseTypeParams(s.typeSpec.TypeParams))
+	field, err := parseField(&ast.Field{Type: s.typeSpec.Type}, resolver)
+	if err != nil {
+		return nil, err
+	}
+	typeDesc := &NamedTypeDesc{
+		Name:        name,
+		Imports:     make(map[string]*ImportDesc),
+		Description: parseDescription(name, doc),
+		Comments:    comments,
+		Annotations: annotations,
+		Occurrences: occurrences,
+		TypeParams:  parseTypeParams(s.typeSpec.TypeParams),
+		DataType:    field.DataType,
+		Type:        field.Type,
+		IsAlias:     s.typeSpec.Assign.IsValid(),
+		Values:      make([]*ValueDesc, 0),
+	}
+	addFieldImports(typeDesc.Imports, s.fileImports, field)
+	if s.consts != nil {
+		for _, value := range s.consts() {
+			if value.DataType == name {
+				typeDesc.Values = append(typeDesc.Values, value)
+			}
+		}
+	}
+	return typeDesc, nil
+}
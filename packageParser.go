@@ -0,0 +1,242 @@
+package go_annotation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PackageParser 包解析器 通过go list一次性加载并类型检查所有匹配的包
+// 匹配的包从源码类型检查 依赖的包读取go list -export生成的导出数据
+type PackageParser struct {
+	patterns []string
+	options  *Options
+}
+
+// NewPackageParser 创建包解析器
+// patterns: 包匹配模式 如 ./... 为空时加载当前目录
+func NewPackageParser(patterns []string, options *Options) *PackageParser {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	return &PackageParser{patterns: patterns, options: options.withDefaults()}
+}
+
+// listedPackage go list -json 输出的包信息
+type listedPackage struct {
+	Dir        string
+	ImportPath string
+	Name       string
+	Export     string            // 导出数据文件 类型检查时用于导入该包
+	GoFiles    []string          // 满足构建约束的源码文件 不含测试文件
+	CgoFiles   []string          // 导入了C的源码文件
+	ImportMap  map[string]string // 源码中的导入路径 -> 实际导入路径 如vendor中的包
+	DepOnly    bool              // 仅作为匹配的包的依赖被加载
+	Error      *struct {
+		Pos string
+		Err string
+	}
+}
+
+// Parse 解析包
+// 包加载错误、类型错误及注解错误不会中断解析 所有诊断信息以ErrorList返回 同时返回已解析的包
+func (p *PackageParser) Parse() ([]*PackageDesc, error) {
+	if p.options.err != nil {
+		return nil, p.options.err
+	}
+	pkgs, err := goList(p.options.Dir, p.options.BuildTags, p.patterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %s", err)
+	}
+	fset := token.NewFileSet()
+	// 所有包共用一个导入器 同一依赖只读取一次导出数据
+	gcImporter := exportImporter(fset, pkgs)
+	result := make([]*PackageDesc, 0, len(pkgs))
+	var diagnostics ErrorList
+	for _, pkg := range pkgs {
+		if pkg.DepOnly {
+			continue
+		}
+		if pkg.Error != nil {
+			diagnostics = append(diagnostics, packageDiagnostic(pkg.Error.Pos, pkg.Error.Err))
+		}
+		pkgDesc, pkgDiagnostics := p.parsePackage(fset, gcImporter, pkg)
+		diagnostics = append(diagnostics, pkgDiagnostics...)
+		result = append(result, pkgDesc)
+	}
+	diagnostics.Sort()
+	return result, diagnostics.Err()
+}
+
+// goList 在dir下执行go list 获取匹配的包及其所有依赖
+func goList(dir string, buildTags []string, patterns []string) ([]*listedPackage, error) {
+	args := []string{"list", "-e", "-json", "-export", "-deps"}
+	if len(buildTags) > 0 {
+		args = append(args, "-tags="+strings.Join(buildTags, ","))
+	}
+	args = append(args, "--")
+	args = append(args, patterns...)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	pkgs := make([]*listedPackage, 0)
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		pkg := &listedPackage{}
+		if err := decoder.Decode(pkg); err != nil {
+			return nil, fmt.Errorf("go list: %s", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+// exportImporter 读取go list -export生成的导出数据的导入器
+func exportImporter(fset *token.FileSet, pkgs []*listedPackage) types.Importer {
+	exports := make(map[string]string, len(pkgs))
+	for _, pkg := range pkgs {
+		exports[pkg.ImportPath] = pkg.Export
+	}
+	return importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		if export := exports[path]; export != "" {
+			return os.Open(export)
+		}
+		return nil, fmt.Errorf("no export data for %s", path)
+	})
+}
+
+// dirImporter 按文件解析时导入目录所在包的依赖 同一目录的文件共用
+// 首次导入时才执行go list 目录不在模块内等无法加载时所有导入均失败
+type dirImporter struct {
+	fset      *token.FileSet
+	dir       string
+	buildTags []string
+
+	loaded    bool
+	importer  types.Importer
+	importMap map[string]string
+	err       error
+}
+
+func newDirImporter(fset *token.FileSet, dir string, options *Options) *dirImporter {
+	return &dirImporter{fset: fset, dir: dir, buildTags: options.BuildTags}
+}
+
+func (d *dirImporter) Import(path string) (*types.Package, error) {
+	if !d.loaded {
+		d.loaded = true
+		d.load()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if mapped, ok := d.importMap[path]; ok {
+		path = mapped
+	}
+	return d.importer.Import(path)
+}
+
+func (d *dirImporter) load() {
+	pkgs, err := goList(d.dir, d.buildTags, []string{"."})
+	if err != nil {
+		d.err = err
+		return
+	}
+	d.importer = exportImporter(d.fset, pkgs)
+	for _, pkg := range pkgs {
+		if !pkg.DepOnly {
+			d.importMap = pkg.ImportMap
+		}
+	}
+}
+
+// parsePackage 解析并类型检查包内的源码 再逐个解析文件
+func (p *PackageParser) parsePackage(fset *token.FileSet, gcImporter types.Importer, pkg *listedPackage) (*PackageDesc, ErrorList) {
+	var diagnostics ErrorList
+	files := make([]*ast.File, 0, len(pkg.GoFiles)+len(pkg.CgoFiles))
+	for _, name := range append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...) {
+		filePath := filepath.Join(pkg.Dir, name)
+		file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+		if err != nil {
+			diagnostics = append(diagnostics, toDiagnostics(err, filePath)...)
+		}
+		// 语法错误时仍使用已解析的部分
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+	info := newTypesInfo()
+	config := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if mapped, ok := pkg.ImportMap[path]; ok {
+				path = mapped
+			}
+			return gcImporter.Import(path)
+		}),
+		FakeImportC: true,
+		Error: func(err error) {
+			if typeErr, ok := err.(types.Error); ok {
+				diagnostics = append(diagnostics, &Diagnostic{Position: typeErr.Fset.Position(typeErr.Pos), Message: typeErr.Msg})
+			}
+		},
+	}
+	typesPkg, _ := config.Check(pkg.ImportPath, fset, files, info)
+	pkgDesc := &PackageDesc{
+		Name:      pkg.Name,
+		PkgPath:   pkg.ImportPath,
+		Dir:       pkg.Dir,
+		Files:     make([]*FileDesc, 0, len(files)),
+		Types:     typesPkg,
+		TypesInfo: info,
+	}
+	docs := make([]*ast.CommentGroup, 0)
+	for _, file := range files {
+		if file.Doc != nil {
+			docs = append(docs, file.Doc)
+		}
+	}
+	// 包注释中的注解错误在解析所在文件时报告 此处忽略
+	pkgDesc.Comments, pkgDesc.Annotations, pkgDesc.Occurrences = parseAnnotations(pkg.Name, p.options, newDiagnosticCollector(fset, ""), docs...)
+	for _, file := range files {
+		filePath := fset.Position(file.Package).Filename
+		if !p.options.acceptFile(filePath) {
+			continue
+		}
+		fileDesc, err := newPackageFileParser(filePath, fset, file, files, pkgDesc, p.options).Parse()
+		if err != nil {
+			diagnostics = append(diagnostics, toDiagnostics(err, filePath)...)
+		}
+		if fileDesc != nil {
+			pkgDesc.Files = append(pkgDesc.Files, fileDesc)
+		}
+	}
+	return pkgDesc, diagnostics
+}
+
+// newTypesInfo 创建记录所有类型检查结果的types.Info
+func newTypesInfo() *types.Info {
+	return &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+}
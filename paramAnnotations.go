@@ -0,0 +1,107 @@
+package go_annotation
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// parseParamComments 解析多行参数列表中参数上方及行尾的注释 作为参数的注解
+// target: 方法或函数的目标名 参数的目标名为 target.参数名
+// 无法获取行号时 参数之间的注释均视为下一个参数的注释
+func parseParamComments(target string, fieldList *ast.FieldList, fields []*Field, comments []*ast.CommentGroup, options *Options, diagnostics *diagnosticCollector) {
+	if fieldList == nil || !fieldList.Opening.IsValid() {
+		return
+	}
+	groups := make(map[*ast.Field][]*ast.CommentGroup)
+	for _, group := range comments {
+		if group.Pos() < fieldList.Opening || group.End() > fieldList.Closing {
+			continue
+		}
+		var prev, next *ast.Field
+		for _, field := range fieldList.List {
+			if field.End() <= group.Pos() {
+				prev = field
+			} else if next == nil && field.Pos() >= group.End() {
+				next = field
+			}
+		}
+		// 行尾注释属于同一行的上一个参数
+		if prev != nil && (next == nil || sameLine(diagnostics.fset, prev.End(), group.Pos())) {
+			groups[prev] = append(groups[prev], group)
+		} else if next != nil {
+			groups[next] = append(groups[next], group)
+		}
+	}
+	// 分组参数 a, b int 展开为多个字段 注释属于组中的每个字段
+	i := 0
+	for _, field := range fieldList.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for ; count > 0 && i < len(fields); count-- {
+			if len(groups[field]) > 0 {
+				fields[i].Comments, fields[i].Annotations, fields[i].Occurrences = parseAnnotations(target+"."+fields[i].Name, options, diagnostics, groups[field]...)
+			}
+			i++
+		}
+	}
+}
+
+func sameLine(fset *token.FileSet, a token.Pos, b token.Pos) bool {
+	return fset != nil && fset.Position(a).Line == fset.Position(b).Line
+}
+
+// bindParamAnnotations 将方法上的参数注解绑定到参数 如 @param(name="id", in="path") 绑定到参数id
+// 通过属性name匹配参数名 数组模式等按位置命名属性的注解使用第一个属性
+// 注解同时保留在方法上 参数不存在时记录诊断信息
+func bindParamAnnotations(target string, occurrences []*AnnotationOccurrence, params []*Field, options *Options, diagnostics *diagnosticCollector) {
+	if options.ParamAnnotation == "" {
+		return
+	}
+	for _, occurrence := range occurrences {
+		if occurrence.Name != options.ParamAnnotation {
+			continue
+		}
+		name, ok := occurrence.Attributes["name"]
+		if !ok {
+			name, ok = occurrence.Attributes["0"]
+		}
+		if !ok {
+			diagnostics.addAt(occurrence.Position, target, "annotation %s has no parameter name", occurrence.Name)
+			continue
+		}
+		var param *Field
+		for _, field := range params {
+			if field.Name == name {
+				param = field
+				break
+			}
+		}
+		if param == nil {
+			diagnostics.addAt(occurrence.Position, target, "annotation %s refers to unknown parameter %s", occurrence.Name, name)
+			continue
+		}
+		if param.Annotations == nil {
+			param.Annotations = make(map[string]*Annotation)
+		}
+		annotation, ok := param.Annotations[occurrence.Name]
+		if !ok {
+			annotation = &Annotation{Name: occurrence.Name, Attributes: []map[string]string{}}
+			param.Annotations[occurrence.Name] = annotation
+		}
+		// 复制属性 填充默认值时不影响方法上的注解
+		attribute := make(map[string]string, len(occurrence.Attributes))
+		for key, value := range occurrence.Attributes {
+			attribute[key] = value
+		}
+		// 本次出现没有属性值时也追加空map 保持Values与Attributes按下标一一对应
+		values := make(map[string]*AnnotationValue, len(occurrence.Values))
+		for key, value := range occurrence.Values {
+			values[key] = value
+		}
+		annotation.Attributes = append(annotation.Attributes, attribute)
+		annotation.Values = append(annotation.Values, values)
+		param.Occurrences = append(param.Occurrences, occurrence)
+	}
+}
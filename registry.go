@@ -0,0 +1,278 @@
+package go_annotation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type TargetKind string // 注解目标类型
+
+const (
+	TargetStruct    TargetKind = "struct"    // 结构体
+	TargetInterface TargetKind = "interface" // 接口
+	TargetMethod    TargetKind = "method"    // 结构体或接口的方法
+	TargetField     TargetKind = "field"     // 结构体字段
+	TargetFunc      TargetKind = "func"      // 包级函数
+	TargetType      TargetKind = "type"      // 结构体及接口以外的具名类型
+	TargetConst     TargetKind = "const"     // 常量组或常量
+	TargetVar       TargetKind = "var"       // 变量组或变量
+	TargetPackage   TargetKind = "package"   // 包或文件
+	TargetParam     TargetKind = "param"     // 方法或函数的参数 仅参数上的注释 绑定到参数的方法注解按方法校验
+)
+
+// AttributeDef 注解属性定义
+type AttributeDef struct {
+	Name     string              // 属性名称 数组模式为位置 如 0、1
+	Type     AnnotationValueKind // 属性值类型 为空时不校验类型
+	Required bool                // 是否必填
+	Default  string              // 默认值 使用注解语法书写 如 "GET"、10s、["a"] 属性缺失时填充
+}
+
+// AnnotationDef 注解定义
+type AnnotationDef struct {
+	Name       string          // 注解名称
+	Targets    []TargetKind    // 允许的目标 为空时不限制
+	Attributes []*AttributeDef // 属性定义
+	Repeatable bool            // 同一目标上是否允许重复
+	// AllowUnknownAttributes 是否允许未定义的属性
+	AllowUnknownAttributes bool
+}
+
+func (d *AnnotationDef) attribute(name string) *AttributeDef {
+	for _, attribute := range d.Attributes {
+		if attribute.Name == name {
+			return attribute
+		}
+	}
+	return nil
+}
+
+func (d *AnnotationDef) allows(target TargetKind) bool {
+	if len(d.Targets) == 0 {
+		return true
+	}
+	for _, t := range d.Targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry 注解定义注册表 通过Options.Registry设置后 解析时校验注解并以诊断信息报告错误
+type Registry struct {
+	mu   sync.RWMutex
+	defs map[string]*AnnotationDef
+}
+
+// NewRegistry 创建注解定义注册表
+func NewRegistry(defs ...*AnnotationDef) (*Registry, error) {
+	r := &Registry{defs: make(map[string]*AnnotationDef)}
+	if err := r.Register(defs...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Register 注册注解定义 名称重复或默认值无效时返回错误
+func (r *Registry) Register(defs ...*AnnotationDef) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, def := range defs {
+		if def == nil || def.Name == "" {
+			return fmt.Errorf("annotation name is empty")
+		}
+		if _, ok := r.defs[def.Name]; ok {
+			return fmt.Errorf("annotation %s is already registered", def.Name)
+		}
+		for _, attribute := range def.Attributes {
+			if attribute.Default == "" {
+				continue
+			}
+			value, err := parseAnnotationValue(attribute.Default)
+			if err != nil {
+				return fmt.Errorf("invalid default value of %s.%s: %s", def.Name, attribute.Name, err)
+			}
+			if !matchValueKind(attribute.Type, value) {
+				return fmt.Errorf("default value of %s.%s must be %s, got %s", def.Name, attribute.Name, attribute.Type, value.Kind)
+			}
+		}
+		r.defs[def.Name] = def
+	}
+	return nil
+}
+
+// Lookup 查找注解定义
+func (r *Registry) Lookup(name string) (*AnnotationDef, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.defs[name]
+	return def, ok
+}
+
+// matchValueKind 属性值是否符合定义的类型 整数可作为浮点数 标识符可作为字符串
+func matchValueKind(kind AnnotationValueKind, value *AnnotationValue) bool {
+	switch {
+	case kind == "" || kind == value.Kind:
+		return true
+	case kind == AnnotationValueFloat:
+		return value.Kind == AnnotationValueInt
+	case kind == AnnotationValueString:
+		return value.Kind == AnnotationValueIdent
+	default:
+		return false
+	}
+}
+
+// attributeValue 获取注解单次出现中的属性值 数组模式等无类型信息时按字面量解析
+func attributeValue(annotation *Annotation, index int, name string) *AnnotationValue {
+	if index < len(annotation.Values) {
+		if value, ok := annotation.Values[index][name]; ok {
+			return value
+		}
+	}
+	raw := annotation.Attributes[index][name]
+	if value, err := parseAnnotationValue(raw); err == nil {
+		return value
+	}
+	return &AnnotationValue{Kind: AnnotationValueString, Raw: raw, String: raw}
+}
+
+// validateAnnotations 校验文件中所有目标上的注解 并为缺失的属性填充默认值
+func (f *FileParser) validateAnnotations(fileDesc *FileDesc, diagnostics *diagnosticCollector) {
+	registry := f.options.Registry
+	if registry == nil {
+		return
+	}
+	validate := func(target string, kind TargetKind, annotations map[string]*Annotation) {
+		registry.validate(f.options.AnnotationParser, target, kind, diagnostics.annotations[target], diagnostics)
+		registry.applyDefaults(annotations)
+	}
+	validate(fileDesc.FileName, TargetPackage, fileDesc.Annotations)
+	validateParams := func(target string, params []*Field) {
+		for _, param := range params {
+			validate(target+"."+param.Name, TargetParam, param.Annotations)
+		}
+	}
+	for _, structDesc := range fileDesc.Structs {
+		validate(structDesc.Name, TargetStruct, structDesc.Annotations)
+		for _, field := range structDesc.Fields {
+			validate(structDesc.Name+"."+field.Name, TargetField, field.Annotations)
+		}
+		for _, method := range structDesc.Methods {
+			validate(structDesc.Name+"."+method.Name, TargetMethod, method.Annotations)
+			validateParams(structDesc.Name+"."+method.Name, method.Params)
+		}
+	}
+	for _, interfaceDesc := range fileDesc.Interfaces {
+		validate(interfaceDesc.Name, TargetInterface, interfaceDesc.Annotations)
+		for _, method := range interfaceDesc.Methods {
+			validate(interfaceDesc.Name+"."+method.Name, TargetMethod, method.Annotations)
+			validateParams(interfaceDesc.Name+"."+method.Name, method.Params)
+		}
+	}
+	for _, funcDesc := range fileDesc.Funcs {
+		validate(funcDesc.Name, TargetFunc, funcDesc.Annotations)
+		validateParams(funcDesc.Name, funcDesc.Params)
+	}
+	for _, typeDesc := range fileDesc.NamedTypes {
+		validate(typeDesc.Name, TargetType, typeDesc.Annotations)
+	}
+	validateGroups := func(groups []*ValueGroupDesc, kind TargetKind) {
+		for _, group := range groups {
+			if len(group.Values) > 0 {
+				validate(string(kind)+" "+group.Values[0].Name, kind, group.Annotations)
+			}
+			for _, value := range group.Values {
+				validate(value.Name, kind, value.Annotations)
+			}
+		}
+	}
+	validateGroups(fileDesc.Consts, TargetConst)
+	validateGroups(fileDesc.Vars, TargetVar)
+}
+
+// validate 逐条校验目标上的注解
+func (r *Registry) validate(parser AnnotationParser, target string, kind TargetKind, comments []*atComment, diagnostics *diagnosticCollector) {
+	seen := make(map[string]bool)
+	for _, com := range comments {
+		for name, annotation := range parser.Parse([]string{com.text}) {
+			def, ok := r.Lookup(name)
+			if !ok {
+				diagnostics.add(com.pos, target, "unknown annotation %s", name)
+				continue
+			}
+			if !def.allows(kind) {
+				diagnostics.add(com.pos, target, "annotation %s is not allowed on %s", name, kind)
+			}
+			if seen[name] && !def.Repeatable {
+				diagnostics.add(com.pos, target, "annotation %s is not repeatable", name)
+			}
+			seen[name] = true
+			attributes := annotation.Attributes
+			if len(attributes) == 0 {
+				attributes = []map[string]string{{}}
+			}
+			for i, attribute := range attributes {
+				for _, attributeDef := range def.Attributes {
+					if _, ok := attribute[attributeDef.Name]; !ok && attributeDef.Required {
+						diagnostics.add(com.pos, target, "missing required attribute %s of %s", attributeDef.Name, name)
+					}
+				}
+				keys := make([]string, 0, len(attribute))
+				for key := range attribute {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					attributeDef := def.attribute(key)
+					if attributeDef == nil {
+						if !def.AllowUnknownAttributes {
+							diagnostics.add(com.pos, target, "unknown attribute %s of %s", key, name)
+						}
+						continue
+					}
+					if value := attributeValue(annotation, i, key); !matchValueKind(attributeDef.Type, value) {
+						diagnostics.add(com.pos, target, "attribute %s of %s must be %s, got %s %s", key, name, attributeDef.Type, value.Kind, strings.TrimSpace(value.Raw))
+					}
+				}
+			}
+		}
+	}
+}
+
+// applyDefaults 为缺失的属性填充默认值 注解无属性时新增一组仅含默认值的属性
+func (r *Registry) applyDefaults(annotations map[string]*Annotation) {
+	for name, annotation := range annotations {
+		def, ok := r.Lookup(name)
+		if !ok {
+			continue
+		}
+		defaults := make(map[string]*AnnotationValue)
+		for _, attributeDef := range def.Attributes {
+			if attributeDef.Default != "" {
+				defaults[attributeDef.Name], _ = parseAnnotationValue(attributeDef.Default)
+			}
+		}
+		if len(defaults) == 0 {
+			continue
+		}
+		if len(annotation.Attributes) == 0 {
+			annotation.Attributes = append(annotation.Attributes, map[string]string{})
+			annotation.Values = append(annotation.Values, map[string]*AnnotationValue{})
+		}
+		for i, attribute := range annotation.Attributes {
+			for key, value := range defaults {
+				if _, ok := attribute[key]; ok {
+					continue
+				}
+				attribute[key] = value.text()
+				if i < len(annotation.Values) {
+					annotation.Values[i][key] = value
+				}
+			}
+		}
+	}
+}
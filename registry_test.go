@@ -0,0 +1,86 @@
+package go_annotation
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	registry, err := NewRegistry(
+		&AnnotationDef{
+			Name:       "service",
+			Targets:    []TargetKind{TargetStruct, TargetInterface},
+			Attributes: []*AttributeDef{{Name: "name", Type: AnnotationValueString, Required: true}},
+		},
+		&AnnotationDef{
+			Name:       "route",
+			Targets:    []TargetKind{TargetMethod, TargetFunc},
+			Repeatable: true,
+			Attributes: []*AttributeDef{
+				{Name: "path", Type: AnnotationValueString, Required: true},
+				{Name: "method", Type: AnnotationValueString, Default: `"GET"`},
+				{Name: "retry", Type: AnnotationValueInt},
+				{Name: "timeout", Type: AnnotationValueDuration, Default: "1s"},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestRegistryValidate(t *testing.T) {
+	options := &Options{Mode: AnnotationModeMap, Registry: newTestRegistry(t)}
+	fileDesc, err := NewParser(options).GetFileDesc("test/data/registry/registry.go")
+	var list ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("GetFileDesc() error = %v, want ErrorList", err)
+	}
+	want := []struct {
+		line    int
+		target  string
+		message string
+	}{
+		{5, "UserService", "annotation service is not repeatable"},
+		{7, "UserService.ID", "unknown annotation column"},
+		{12, "UserService.Get", "attribute retry of route must be int, got string \"3\""},
+		{13, "UserService.Get", "unknown annotation unknown"},
+		{22, "UserService.Create", "missing required attribute path of route"},
+		{22, "UserService.Create", "unknown attribute extra of route"},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("GetFileDesc() got %d diagnostics, want %d: %v", len(list), len(want), list)
+	}
+	for i, w := range want {
+		d := list[i]
+		if d.Position.Line != w.line || d.Target != w.target || d.Message != w.message {
+			t.Errorf("diagnostic[%d] = %s, want line %d %s: %s", i, d, w.line, w.target, w.message)
+		}
+	}
+	// 缺失的属性填充默认值
+	route := fileDesc.Structs[0].Methods[1].Annotations["route"]
+	if len(route.Attributes) != 2 || route.Attributes[1]["method"] != "GET" || route.Values[0]["timeout"].Duration != 5*time.Second || route.Values[1]["timeout"].Duration != time.Second {
+		t.Errorf("GetFileDesc() route = %+v, want default method and timeout", route.Attributes)
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name string
+		def  *AnnotationDef
+	}{
+		{name: "名称为空", def: &AnnotationDef{}},
+		{name: "重复注册", def: &AnnotationDef{Name: "route"}},
+		{name: "默认值无效", def: &AnnotationDef{Name: "a", Attributes: []*AttributeDef{{Name: "b", Default: `"x`}}}},
+		{name: "默认值类型错误", def: &AnnotationDef{Name: "a", Attributes: []*AttributeDef{{Name: "b", Type: AnnotationValueInt, Default: `"x"`}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := newTestRegistry(t).Register(tt.def); err == nil {
+				t.Errorf("Register() error = nil, want error")
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package go_annotation
 
 import (
 	"go/ast"
-	"strings"
+	"strconv"
 	"unicode"
 )
 
@@ -12,116 +12,233 @@ type StructParser struct {
 	typeSpec    *ast.TypeSpec
 	genDecl     *ast.GenDecl
 	fileImports map[string]*ImportDesc
+	options     *Options
+	resolver    *typeResolver
+	diagnostics *diagnosticCollector
+
+	packageFiles []*ast.File    // 同包的所有文件 为空时仅从当前文件收集方法
+	embeds       *embedResolver // 嵌入类型声明查找 为空时仅查找同包的文件
+}
+
+// structMethod 结构体方法及其所在文件的导入信息
+type structMethod struct {
+	funcDecl *ast.FuncDecl
+	imports  map[string]*ImportDesc
+	resolver *typeResolver
+	comments []*ast.CommentGroup // 方法所在文件的注释 用于解析参数上的注释
 }
 
 func NewStructParser(serviceName string,
 	typeSpec *ast.TypeSpec,
 	genDecl *ast.GenDecl,
 	file *ast.File,
-	fileImports map[string]*ImportDesc) *StructParser {
+	fileImports map[string]*ImportDesc,
+	options *Options) *StructParser {
 	return &StructParser{serviceName: serviceName,
 		typeSpec:    typeSpec,
 		genDecl:     genDecl,
 		file:        file,
-		fileImports: fileImports}
+		fileImports: fileImports,
+		options:     options.withDefaults(),
+		resolver:    &typeResolver{imports: fileImports, packageName: file.Name.Name},
+		diagnostics: newDiagnosticCollector(nil, "")}
 }
 
 func (s *StructParser) Parse() (*StructDesc, error) {
-	comments := parseAtComments(s.genDecl.Doc)
+	if s.options.err != nil {
+		return nil, s.options.err
+	}
+	comments, annotations, occurrences := parseAnnotations(s.serviceName, s.options, s.diagnostics, s.genDecl.Doc)
 	description := parseDescription(s.serviceName, s.genDecl.Doc)
 	funcList, err := s.getFuncList()
 	if err != nil {
 		return nil, err
 	}
-	if funcList == nil || len(funcList) == 0 {
-		return nil, nil
+	fields, err := s.parserFields()
+	if err != nil {
+		return nil, err
 	}
 	methods := make([]*MethodDesc, 0)
 	for _, f := range funcList {
-		methodDesc, err := s.parserMethod(f)
+		methodDesc, err := s.parserMethod(f.funcDecl, f.resolver, f.comments)
 		if err != nil {
 			return nil, err
 		}
 		methods = append(methods, methodDesc)
 	}
+	imports := s.parserImports(fields, methods, funcList)
+	embeds, promoted := s.parserEmbeds(imports)
+	methods = appendPromoted(methods, promoted)
+	// 结构体本身、方法及字段均无注解时忽略该结构体
+	if len(methods) == 0 && len(comments) == 0 && !hasFieldAnnotations(fields) {
+		return nil, nil
+	}
 	sDesc := &StructDesc{
 		Name:        s.serviceName,
 		Description: description,
+		Fields:      fields,
+		Embeds:      embeds,
 		Methods:     methods,
-		Imports:     s.parserImports(methods),
+		Imports:     imports,
 		Comments:    comments,
-		Annotations: getAnnotationParser(currentAnnotationMode).Parse(comments),
+		Annotations: annotations,
+		Occurrences: occurrences,
+		TypeParams:  parseTypeParams(s.typeSpec.TypeParams),
 	}
 	return sDesc, nil
 }
 
-func (s *StructParser) getFuncList() ([]*ast.FuncDecl, error) {
-	list := make([]*ast.FuncDecl, 0)
-	for _, decl := range s.file.Decls {
-		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
-			if unicode.IsUpper(rune(funcDecl.Name.Name[0])) && funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
-				if starExpr, ok := funcDecl.Recv.List[0].Type.(*ast.StarExpr); ok {
-					if ident, ok := starExpr.X.(*ast.Ident); ok {
-						if ident.Name == s.serviceName && funcDecl.Doc != nil && strings.Contains(funcDecl.Doc.Text(), AnnotationPrefix) {
-							list = append(list, funcDecl)
-						}
-					}
+// parserFields 解析结构体字段 a, b int 会被拆分为两个字段
+func (s *StructParser) parserFields() ([]*Field, error) {
+	fields := make([]*Field, 0)
+	structType, ok := s.typeSpec.Type.(*ast.StructType)
+	if !ok || structType.Fields == nil {
+		return fields, nil
+	}
+	resolver := s.resolver.withTypeParams(parseTypeParams(s.typeSpec.TypeParams))
+	for _, astField := range structType.Fields.List {
+		names := make([]string, 0, len(astField.Names))
+		for _, name := range astField.Names {
+			names = append(names, name.Name)
+		}
+		if len(names) == 0 {
+			names = append(names, embeddedName(astField.Type))
+		}
+		for _, name := range names {
+			field, err := parseField(astField, resolver)
+			if err != nil {
+				return nil, err
+			}
+			field.Name = name
+			field.IsEmbedded = len(astField.Names) == 0
+			target := s.serviceName + "." + name
+			if astField.Tag != nil {
+				if field.Tag, err = strconv.Unquote(astField.Tag.Value); err != nil {
+					s.diagnostics.add(astField.Tag.Pos(), target, "invalid tag: %s", err)
 				}
+				field.Tags = parseStructTag(field.Tag)
 			}
+			field.Comments, field.Annotations, field.Occurrences = parseAnnotations(target, s.options, s.diagnostics, astField.Doc, astField.Comment)
+			field.Description = parseDescription(name, astField.Doc)
+			fields = append(fields, field)
 		}
 	}
-	return list, nil
+	return fields, nil
 }
 
-func (s *StructParser) parserMethod(method *ast.FuncDecl) (methodDesc *MethodDesc, err error) {
-	methodDesc = &MethodDesc{}
-	methodDesc.Name = method.Name.Name
-	// params
-	params := make([]*Field, 0)
-	if method.Type.Params != nil {
-		for _, param := range method.Type.Params.List {
-			field, err := parseField(param)
-			if err != nil {
-				return nil, err
-			}
-			params = append(params, field)
+// parserEmbeds 解析嵌入字段 开启FlattenEmbeds时同时返回嵌入类型的方法
+func (s *StructParser) parserEmbeds(imports map[string]*ImportDesc) ([]*EmbedDesc, []*MethodDesc) {
+	embeds := make([]*EmbedDesc, 0)
+	var promoted []*MethodDesc
+	structType, ok := s.typeSpec.Type.(*ast.StructType)
+	if !ok || structType.Fields == nil {
+		return embeds, promoted
+	}
+	resolver := s.embedResolver()
+	for _, astField := range structType.Fields.List {
+		if len(astField.Names) > 0 {
+			continue
+		}
+		decl := resolver.lookup(astField.Type, s.fileImports, resolver.packageFiles, s.resolver.packagePath)
+		embed := parseEmbed(astField.Type, s.resolver, decl, s.options)
+		embeds = append(embeds, embed)
+		if s.options.FlattenEmbeds {
+			promoted = append(promoted, resolver.promotedMethods(embed, decl, s.options, imports)...)
 		}
 	}
-	methodDesc.Params = params
-	// results
-	results := make([]*Field, 0)
-	if method.Type.Results != nil {
-		for _, result := range method.Type.Results.List {
-			field, err := parseField(result)
-			if err != nil {
-				return nil, err
+	return embeds, promoted
+}
+
+func (s *StructParser) embedResolver() *embedResolver {
+	if s.embeds == nil {
+		files := s.packageFiles
+		if len(files) == 0 {
+			files = []*ast.File{s.file}
+		}
+		s.embeds = newEmbedResolver(nil, "", files, s.resolver.packagePath)
+	}
+	return s.embeds
+}
+
+func hasFieldAnnotations(fields []*Field) bool {
+	for _, field := range fields {
+		if len(field.Comments) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getFuncList 获取结构体带有注解的导出方法 先当前文件 后同包其他文件
+func (s *StructParser) getFuncList() ([]*structMethod, error) {
+	list := make([]*structMethod, 0)
+	files := []*ast.File{s.file}
+	for _, file := range s.packageFiles {
+		if file != s.file {
+			files = append(files, file)
+		}
+	}
+	for _, file := range files {
+		imports := s.fileImports
+		resolver := s.resolver
+		if file != s.file {
+			imports = getFileImports(file)
+			fileResolver := *s.resolver
+			fileResolver.imports = imports
+			resolver = &fileResolver
+		}
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || !unicode.IsUpper(rune(funcDecl.Name.Name[0])) || funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+				continue
+			}
+			typeName, _ := receiverType(funcDecl.Recv.List[0].Type)
+			if typeName == s.serviceName && s.options.hasAnnotations(funcDecl.Doc) {
+				list = append(list, &structMethod{funcDecl: funcDecl, imports: imports, resolver: resolver, comments: file.Comments})
 			}
-			results = append(results, field)
 		}
 	}
-	methodDesc.Results = results
+	return list, nil
+}
+
+func (s *StructParser) parserMethod(method *ast.FuncDecl, resolver *typeResolver, comments []*ast.CommentGroup) (methodDesc *MethodDesc, err error) {
+	methodDesc = &MethodDesc{}
+	methodDesc.Name = method.Name.Name
+	// receiver
+	receiver := method.Recv.List[0]
+	if len(receiver.Names) > 0 {
+		methodDesc.ReceiverName = receiver.Names[0].Name
+	}
+	methodDesc.ReceiverKind = ReceiverKindValue
+	if _, isPtr := receiverType(receiver.Type); isPtr {
+		methodDesc.ReceiverKind = ReceiverKindPointer
+	}
+	methodDesc.TypeParams = receiverTypeParams(receiver.Type, parseTypeParams(s.typeSpec.TypeParams))
+	// params and results
+	if methodDesc.Params, methodDesc.Results, err = parseSignature(method.Type, resolver.withTypeParams(methodDesc.TypeParams)); err != nil {
+		return nil, err
+	}
 	// comment
-	methodDesc.Comments = parseAtComments(method.Doc)
+	target := s.serviceName + "." + methodDesc.Name
+	methodDesc.Comments, methodDesc.Annotations, methodDesc.Occurrences = parseAnnotations(target, s.options, s.diagnostics, method.Doc)
+	parseParamComments(target, method.Type.Params, methodDesc.Params, comments, s.options, s.diagnostics)
+	bindParamAnnotations(target, methodDesc.Occurrences, methodDesc.Params, s.options, s.diagnostics)
 	methodDesc.Description = parseDescription(methodDesc.Name, method.Doc)
-	methodDesc.Annotations = getAnnotationParser(currentAnnotationMode).Parse(methodDesc.Comments)
 	return methodDesc, err
 }
 
-func (s *StructParser) parserImports(methods []*MethodDesc) (imports map[string]*ImportDesc) {
+// parserImports 收集字段及方法用到的导入 方法按其所在文件的导入信息查找
+func (s *StructParser) parserImports(structFields []*Field, methods []*MethodDesc, funcList []*structMethod) (imports map[string]*ImportDesc) {
 	imports = make(map[string]*ImportDesc)
-	fields := make([]*Field, 0)
-	for _, method := range methods {
-		for _, param := range method.Params {
-			fields = append(fields, param)
-		}
-		for _, result := range method.Results {
-			fields = append(fields, result)
+	addImports := func(fields []*Field, fileImports map[string]*ImportDesc) {
+		for _, field := range fields {
+			addFieldImports(imports, fileImports, field)
 		}
 	}
-	for _, field := range fields {
-		if imp, ok := s.fileImports[field.PackageName]; ok {
-			imports[field.PackageName] = imp
-		}
+	addImports(structFields, s.fileImports)
+	for i, method := range methods {
+		addImports(method.Params, funcList[i].imports)
+		addImports(method.Results, funcList[i].imports)
 	}
 	return imports
 }
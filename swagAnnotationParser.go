@@ -0,0 +1,139 @@
+package go_annotation
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SwagAnnotationParser swag注解解析器 兼容swaggo风格的注释 如
+//
+//	@Param id path int true "ID"
+//	@Success 200 {object} model.Response{data=[]model.User}
+//	@Router /users/{id} [get]
+//
+// 属性名为其位置 与数组模式相同 双引号字符串作为一个属性并去除引号
+// 括号 {}、[]、() 内的空白不作为分隔符 如 Enums(A, B) 为一个属性
+type SwagAnnotationParser struct{}
+
+func (s *SwagAnnotationParser) Parse(comments []string) map[string]*Annotation {
+	annotations := make(map[string]*Annotation)
+	for _, comment := range comments {
+		// 格式错误时使用已解析的部分 错误由Check报告
+		tokens, _ := splitSwagComment(comment)
+		if len(tokens) == 0 {
+			continue
+		}
+		name := tokens[0].text
+		annotation, ok := annotations[name]
+		if !ok {
+			annotation = &Annotation{Name: name, Attributes: []map[string]string{}}
+			annotations[name] = annotation
+		}
+		if len(tokens) == 1 {
+			continue
+		}
+		attribute := make(map[string]string)
+		values := make(map[string]*AnnotationValue)
+		for i, token := range tokens[1:] {
+			key := strconv.Itoa(i)
+			attribute[key] = token.text
+			values[key] = token.value()
+		}
+		annotation.Attributes = append(annotation.Attributes, attribute)
+		annotation.Values = append(annotation.Values, values)
+	}
+	return annotations
+}
+
+// Check 检查注解格式 字符串须闭合 括号须配对
+func (s *SwagAnnotationParser) Check(comment string) error {
+	_, err := splitSwagComment(comment)
+	return err
+}
+
+type swagToken struct {
+	text   string // 属性值 字符串为去除引号及转义后的值
+	raw    string // 原始文本
+	quoted bool   // 是否为双引号字符串
+}
+
+func (t swagToken) value() *AnnotationValue {
+	if t.quoted {
+		return &AnnotationValue{Kind: AnnotationValueString, Raw: t.raw, String: t.text}
+	}
+	value := literalValue(t.text)
+	value.Raw = t.raw
+	return value
+}
+
+var swagBrackets = map[rune]rune{'{': '}', '[': ']', '(': ')'}
+
+// splitSwagComment 按空白切分swag注释 出错时返回已切分的部分
+func splitSwagComment(comment string) ([]swagToken, error) {
+	var tokens []swagToken
+	for pos := 0; pos < len(comment); {
+		r, size := utf8.DecodeRuneInString(comment[pos:])
+		if unicode.IsSpace(r) {
+			pos += size
+			continue
+		}
+		if r == '"' {
+			end, err := scanSwagString(comment, pos)
+			if err != nil {
+				return tokens, err
+			}
+			text, err := strconv.Unquote(comment[pos:end])
+			if err != nil {
+				return tokens, fmt.Errorf("invalid string %s: %s", comment[pos:end], err)
+			}
+			tokens = append(tokens, swagToken{text: text, raw: comment[pos:end], quoted: true})
+			pos = end
+			continue
+		}
+		start := pos
+		var closers []rune
+		for pos < len(comment) {
+			r, size = utf8.DecodeRuneInString(comment[pos:])
+			if len(closers) == 0 && unicode.IsSpace(r) {
+				break
+			}
+			switch {
+			case r == '"' && len(closers) > 0:
+				end, err := scanSwagString(comment, pos)
+				if err != nil {
+					return tokens, err
+				}
+				pos = end
+				continue
+			case swagBrackets[r] != 0:
+				closers = append(closers, swagBrackets[r])
+			case r == '}' || r == ']' || r == ')':
+				if len(closers) == 0 || closers[len(closers)-1] != r {
+					return tokens, fmt.Errorf("unexpected %q in %s", r, comment[start:pos+size])
+				}
+				closers = closers[:len(closers)-1]
+			}
+			pos += size
+		}
+		if len(closers) > 0 {
+			return tokens, fmt.Errorf("missing %q in %s", closers[len(closers)-1], comment[start:])
+		}
+		tokens = append(tokens, swagToken{text: comment[start:pos], raw: comment[start:pos]})
+	}
+	return tokens, nil
+}
+
+// scanSwagString 扫描从start开始的双引号字符串 返回结束位置
+func scanSwagString(comment string, start int) (int, error) {
+	for pos := start + 1; pos < len(comment); pos++ {
+		switch comment[pos] {
+		case '\\':
+			pos++
+		case '"':
+			return pos + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string %s", comment[start:])
+}
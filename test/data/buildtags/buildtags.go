@@ -0,0 +1,11 @@
+package buildtags
+
+// File 方法分布在带构建约束的文件中
+// @service
+type File struct{}
+
+// Close 关闭
+// @annotation
+func (f *File) Close() error {
+	return nil
+}
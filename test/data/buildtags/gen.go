@@ -0,0 +1,9 @@
+//go:build ignore
+
+package buildtags
+
+// Gen 被忽略的文件中的方法
+// @annotation
+func (f *File) Gen() error {
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !buildtags_never
+
+package buildtags
+
+// Open 满足构建约束的实现
+// @annotation
+func (f *File) Open() error {
+	return nil
+}
@@ -0,0 +1,29 @@
+package diagnostics
+
+import "io"
+
+// BadStruct 注解格式错误的结构体
+// @service(name="bad"
+type BadStruct struct {
+	// @column(name="id")
+	ID int
+	// @column(name="name", size)
+	Name string
+}
+
+// Method1 正常的方法
+// @get(path="/method1")
+func (b *BadStruct) Method1() {}
+
+// Method2 注解格式错误的方法
+// @get(path="/method2") extra
+func (b *BadStruct) Method2() {}
+
+// Reader 嵌入接口的接口
+// @service(name="reader")
+type Reader interface {
+	io.Closer
+	// Read 读取
+	// @get(path="/read")
+	Read(p []byte) (n int, err error)
+}
@@ -0,0 +1,21 @@
+package base
+
+import "context"
+
+// Auditor 其他包中的结构体
+// @audited
+type Auditor struct{}
+
+// Audit 记录操作
+// @log(level="info")
+func (a *Auditor) Audit(ctx context.Context, action string) error {
+	return nil
+}
+
+// Pinger 其他包中的接口
+// @health
+type Pinger interface {
+	// Ping 检查
+	// @get(path="/ping")
+	Ping(ctx context.Context) error
+}
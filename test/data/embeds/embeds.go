@@ -0,0 +1,35 @@
+package embeds
+
+import (
+	"io"
+	"sync"
+
+	"github.com/celt237/go-annotation/test/data/embeds/base"
+)
+
+// Repository 嵌入接口
+// @repository
+type Repository interface {
+	Reader
+	io.Closer
+	base.Pinger
+	// Save 保存
+	// @post(path="/save")
+	Save(value string) error
+}
+
+// UserService 嵌入结构体
+// @service
+type UserService struct {
+	*BaseService
+	base.Auditor
+	sync.Mutex
+	// Name 名称
+	Name string
+}
+
+// Create 创建
+// @post(path="/create")
+func (s *UserService) Create() error {
+	return nil
+}
@@ -0,0 +1,36 @@
+package embeds
+
+// Reader 同包其他文件中的接口
+// @readonly
+type Reader interface {
+	// Get 获取
+	// @get(path="/get")
+	Get(id int64) (string, error)
+}
+
+// BaseService 同包其他文件中的结构体
+// @base
+type BaseService struct {
+	Loader
+}
+
+// Name 名称
+// @get(path="/name")
+func (b *BaseService) Name() string {
+	return ""
+}
+
+// Create 被UserService.Create覆盖
+// @post(path="/base")
+func (b *BaseService) Create() error {
+	return nil
+}
+
+// Loader 多层嵌入
+type Loader struct{}
+
+// Load 加载
+// @get(path="/load")
+func (l Loader) Load() error {
+	return nil
+}
@@ -0,0 +1,41 @@
+package enums
+
+import "time"
+
+// Status 状态
+// @enum(prefix="Status")
+type Status int
+
+const (
+	// StatusPending 待处理
+	// @label(text="待处理")
+	StatusPending Status = iota
+	StatusActive         // @label(text="启用")
+	_
+	StatusDeleted = StatusActive + 10 // 已删除
+)
+
+// Level 未分组的常量
+// @flag
+const Level = "info"
+
+// Timeouts 变量组
+// @config
+var (
+	// DefaultTimeout 默认超时
+	DefaultTimeout       = 3 * time.Second
+	Retries, Backoff int = 3, 100
+)
+
+// ID 类型别名
+// @alias
+type ID = string
+
+// HandlerFunc 函数类型
+// @middleware
+type HandlerFunc func(ctx map[string]interface{}) error
+
+// plain 无注解的常量组不解析
+const (
+	plainA = 1
+)
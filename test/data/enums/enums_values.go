@@ -0,0 +1,7 @@
+package enums
+
+// 同包其他文件中的枚举值
+const (
+	StatusArchived Status = 100 + iota
+	StatusBlocked
+)
@@ -0,0 +1,30 @@
+package fields
+
+import (
+	"github.com/celt237/go-annotation/test/data"
+)
+
+// Base  base
+type Base struct {
+	Id int64 `json:"id"`
+}
+
+// User  test
+// @table(name="user")
+type User struct {
+	*Base
+	data.A1 `json:"a1"`
+
+	// Name  用户名
+	// @validate(min="1", max="32")
+	Name string `json:"name" gorm:"column:name;size:32"`
+
+	Age, Level int `json:"-"` // @validate(min="0")
+
+	password string
+}
+
+// Empty  不含注解的结构体会被忽略
+type Empty struct {
+	Name string `json:"name"`
+}
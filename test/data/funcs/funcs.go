@@ -0,0 +1,38 @@
+package funcs
+
+import (
+	"context"
+
+	"github.com/celt237/go-annotation/test/data"
+)
+
+// GetUser  获取用户
+// @Handler GET /user
+func GetUser(ctx context.Context, req *data.A1) (*data.A2, error) {
+	return nil, nil
+}
+
+// CleanUp  清理
+// @Job 0 0 * * *
+func CleanUp() {
+}
+
+// Max  泛型
+// @Helper
+func Max[T ~int | ~float64](a T, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// noAnnotation 不含注解的函数会被忽略
+func noAnnotation() {
+}
+
+type Service struct{}
+
+// Method 方法不属于包级函数
+// @Handler GET /method
+func (s *Service) Method() {
+}
@@ -0,0 +1,49 @@
+package generics
+
+import (
+	"context"
+	"io"
+)
+
+// Cache 泛型结构体
+// @annotation
+type Cache[K comparable, V any] struct {
+	items   map[K]V
+	buckets [16][]*V
+	ready   chan struct{}
+	notify  chan<- K
+	events  <-chan V
+	loader  func(ctx context.Context, key K) (V, error)
+	onEvict func(K, V)
+	closer  interface {
+		io.Closer
+		Flush() error
+	}
+	meta struct {
+		io.Reader
+		name, desc string
+	}
+}
+
+// Get 泛型接收者
+// @annotation
+func (c *Cache[Key, Value]) Get(key Key, fallbacks ...func() Value) (value Value, ok bool) {
+	return value, false
+}
+
+// Store 泛型接口
+// @annotation
+type Store[T any, PT interface{ *T }] interface {
+	// Load 加载
+	// @annotation
+	Load(ctx context.Context, ids ...int64) (map[int64]PT, error)
+	// Watch 监听
+	// @annotation
+	Watch(ch chan (<-chan T)) Cache[string, []T]
+}
+
+// Sum 泛型函数
+// @annotation
+func Sum[N ~int | ~int64 | ~float64](values [3]N, rest ...N) N {
+	return 0
+}
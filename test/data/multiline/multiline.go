@@ -0,0 +1,22 @@
+package multiline
+
+// Service 多行注解测试
+// @route(
+//
+//	path="/users/{id}",
+//	method="GET",
+//	tags=["user", "admin"],
+//
+// )
+// @auth
+type Service struct{}
+
+/*
+Get 块注释中的注解
+@route(path="/get", method="GET")
+@cache(ttl=10s)
+*/
+func (s *Service) Get() {}
+
+/* @route(path="/list") */
+func (s *Service) List() {}
@@ -0,0 +1,17 @@
+package multiline
+
+/*
+ArrayService 块注释中的数组模式注解
+@service user
+@tags user admin
+*/
+type ArrayService struct{}
+
+/* @get /users */
+func (s *ArrayService) Get() {}
+
+/*
+ * Post 每行以*开头的块注释
+ * @post /users
+ */
+func (s *ArrayService) Post() {}
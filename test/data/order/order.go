@@ -0,0 +1,11 @@
+package order
+
+// Handler 注解顺序测试
+type Handler struct{}
+
+// Get 中间件注解按声明顺序执行
+// @Log
+// @Auth(role="admin")
+// @RateLimit(qps=10)
+// @Auth(role="root")
+func (h *Handler) Get() {}
@@ -0,0 +1,45 @@
+package paramannotations
+
+import "context"
+
+// UserHandler 参数注解测试
+// @controller(path="/users")
+type UserHandler struct{}
+
+// Get 方法上的参数注解按name绑定到参数
+// @route(method="GET", path="/users/{id}")
+// @param(name="id", in="path")
+// @param(name="fields", in="query")
+func (h *UserHandler) Get(ctx context.Context, id int64, fields []string) (string, error) {
+	return "", nil
+}
+
+// Update 多行参数列表中的注释
+// @route(method="PUT", path="/users/{id}")
+func (h *UserHandler) Update(
+	ctx context.Context,
+	// @param(in="path")
+	id int64,
+	token string, // @header(name="X-Token")
+	// 请求体
+	// @body
+	name, email string,
+) error {
+	return nil
+}
+
+// UserService 接口方法的参数注解
+type UserService interface {
+	// Delete 删除用户
+	// @param(name="id", in="path")
+	Delete(ctx context.Context, id int64) error
+}
+
+// CreateUser 函数的参数注解
+// @param(name="name", in="form")
+func CreateUser(
+	name string,
+	age int, // @validate(min="0")
+) error {
+	return nil
+}
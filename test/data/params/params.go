@@ -0,0 +1,19 @@
+package params
+
+import "context"
+
+// Service 参数测试
+type Service interface {
+	// Grouped 分组参数及命名返回值
+	// @annotation
+	Grouped(ctx context.Context, a, b int, names ...string) (n, m int, err error)
+	// Unnamed 未命名参数及返回值
+	// @annotation
+	Unnamed(context.Context, int, ...string) (int, error)
+}
+
+// Blank 空白标识符及名称冲突
+// @annotation
+func Blank(_ int, p0 string, _ bool) (r0 error, _ error) {
+	return nil, nil
+}
@@ -0,0 +1,4 @@
+// Package pkgdoc 包级注解测试
+// @basePath(path="/api/v1")
+// @generate(target="mocks")
+package pkgdoc
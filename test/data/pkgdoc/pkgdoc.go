@@ -0,0 +1,11 @@
+// Copyright 2024 go-annotation authors
+// @license(name="MIT")
+
+//go:build !ignore
+
+// @generate(target="client")
+package pkgdoc
+
+// Service 服务
+// @service
+type Service struct{}
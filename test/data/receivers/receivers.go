@@ -0,0 +1,23 @@
+package receivers
+
+import (
+	"github.com/celt237/go-annotation/test/data"
+)
+
+// Repo  test
+// @annotation
+type Repo[K comparable] struct {
+	items map[K]*data.A1
+}
+
+// Get  指针接收者
+// @annotation
+func (r *Repo[K]) Get(key K) *data.A1 {
+	return r.items[key]
+}
+
+// Len  值接收者
+// @annotation
+func (r Repo[K]) Len() int {
+	return len(r.items)
+}
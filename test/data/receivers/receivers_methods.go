@@ -0,0 +1,19 @@
+package receivers
+
+import (
+	d "github.com/celt237/go-annotation/test/data"
+)
+
+// Put  其他文件中的方法
+// @annotation
+func (r *Repo[K]) Put(key K, value *d.A2) {
+}
+
+// Service  test
+// @annotation
+type Service struct{}
+
+// Call  值接收者且无接收者变量名
+// @annotation
+func (Service) Call() {
+}
@@ -0,0 +1,23 @@
+package registry
+
+// UserService 注解校验测试
+// @service(name="user")
+// @service(name="user2")
+type UserService struct {
+	// @column(name="id")
+	ID int
+}
+
+// Get 获取用户
+// @route(path="/users", retry="3")
+// @unknown
+func (s *UserService) Get() {}
+
+// List 获取用户列表
+// @route(path="/users/list", timeout=5s)
+// @route(path="/v2/users/list")
+func (s *UserService) List() {}
+
+// Create 创建用户
+// @route(method="POST", extra=1)
+func (s *UserService) Create() {}
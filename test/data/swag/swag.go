@@ -0,0 +1,19 @@
+package swag
+
+// UserHandler swag注解测试
+// @Tags users
+type UserHandler struct{}
+
+// GetUser 获取用户
+// @Summary 获取用户
+// @Description 根据 ID 获取用户
+// @Param id path int true "用户 ID"
+// @Param fields query []string false "返回字段" collectionFormat(multi)
+// @Success 200 {object} Response{data=User} "成功"
+// @Failure 404 {string} string "用户不存在"
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser() {}
+
+type User struct{}
+
+type Response struct{}
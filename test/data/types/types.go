@@ -0,0 +1,29 @@
+package types
+
+import (
+	"bytes"
+	ctx "context"
+	. "github.com/celt237/go-annotation/test/data"
+	d "github.com/celt237/go-annotation/test/data"
+	"io"
+)
+
+type Local struct {
+	Name string
+}
+
+// TypeService  test
+// @annotation
+type TypeService interface {
+	// Method1  test1
+	// @annotation
+	Method1(c ctx.Context, a1 *d.A1, a2 A2, local *Local) error
+
+	// Method2  test2
+	// @annotation
+	Method2(m map[string][]*d.A3, ch chan int, f func() error) ([2]Local, any)
+
+	// Method3  test3 导入的包仅用于复合类型
+	// @annotation
+	Method3(f func(io.Reader) error) []*bytes.Buffer
+}
@@ -0,0 +1,10 @@
+package values
+
+// Handler 属性值类型测试
+// @service(name="handler", desc="say \"hi\"\tthere", raw=`C:\path`)
+type Handler struct{}
+
+// Get 各类型的属性值
+// @route(path="/a,b", retry=3, mask=0x1f, ratio=0.5, enabled=true, timeout=1m30s, method=GET)
+// @route(methods=["GET", "POST"], headers=[@Header(name="x", required=true), @Header(name="y")], empty=[])
+func (h *Handler) Get() {}
@@ -0,0 +1,274 @@
+package go_annotation
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// basicTypes 内置基础类型
+var basicTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "uintptr": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// builtinNamedTypes 内置具名类型
+var builtinNamedTypes = map[string]bool{
+	"error": true, "any": true, "comparable": true,
+}
+
+// typeResolver 类型解析器
+// 有类型检查信息时基于go/types解析 否则根据文件的导入信息解析
+// 后者无法识别点导入的类型 会将其视为当前包的类型 仅在类型检查失败时使用
+type typeResolver struct {
+	info        *types.Info
+	imports     map[string]*ImportDesc
+	packagePath string          // 当前包完整包名
+	packageName string          // 当前包名
+	typeParams  map[string]bool // 作用域内的类型参数 无类型检查信息时用于区分类型参数与当前包的类型
+}
+
+// withTypeParams 返回在当前作用域上增加类型参数的解析器 不修改原解析器
+func (r *typeResolver) withTypeParams(typeParams []*TypeParam) *typeResolver {
+	if len(typeParams) == 0 {
+		return r
+	}
+	scoped := *r
+	scoped.typeParams = make(map[string]bool, len(r.typeParams)+len(typeParams))
+	for name := range r.typeParams {
+		scoped.typeParams[name] = true
+	}
+	for _, typeParam := range typeParams {
+		scoped.typeParams[typeParam.Name] = true
+	}
+	return &scoped
+}
+
+func (r *typeResolver) resolve(expr ast.Expr) *TypeDesc {
+	if r.info != nil {
+		// 导入的包无法加载时类型无效 退回根据导入信息解析
+		if t := r.info.TypeOf(expr); t != nil && isValidType(t) {
+			return typeDescFromTypes(t)
+		}
+	}
+	return r.resolveExpr(expr)
+}
+
+func (r *typeResolver) resolveExpr(expr ast.Expr) *TypeDesc {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		// 类型参数可能与内置类型同名 优先判断
+		if r.typeParams[t.Name] {
+			return &TypeDesc{Kind: TypeKindTypeParam, TypeName: t.Name}
+		}
+		if basicTypes[t.Name] {
+			return &TypeDesc{Kind: TypeKindBasic, TypeName: t.Name}
+		}
+		if builtinNamedTypes[t.Name] {
+			return &TypeDesc{Kind: TypeKindNamed, TypeName: t.Name}
+		}
+		return &TypeDesc{Kind: TypeKindNamed, ImportPath: r.packagePath, PackageName: r.packageName, TypeName: t.Name}
+	case *ast.SelectorExpr:
+		typeDesc := &TypeDesc{Kind: TypeKindNamed, TypeName: t.Sel.Name}
+		if ident, ok := t.X.(*ast.Ident); ok {
+			typeDesc.PackageName = ident.Name
+			if imp, ok := r.imports[ident.Name]; ok {
+				typeDesc.ImportPath = imp.Path
+				// 导入别名不是包名 以导入路径最后一段作为包名
+				parts := strings.Split(imp.Path, "/")
+				typeDesc.PackageName = parts[len(parts)-1]
+			}
+		}
+		return typeDesc
+	case *ast.StarExpr:
+		return &TypeDesc{Kind: TypeKindPointer, Elem: r.resolve(t.X)}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return &TypeDesc{Kind: TypeKindSlice, Elem: r.resolve(t.Elt)}
+		}
+		return &TypeDesc{Kind: TypeKindArray, Elem: r.resolve(t.Elt)}
+	case *ast.Ellipsis:
+		// 可变参数
+		return &TypeDesc{Kind: TypeKindSlice, Elem: r.resolve(t.Elt)}
+	case *ast.MapType:
+		return &TypeDesc{Kind: TypeKindMap, Key: r.resolve(t.Key), Elem: r.resolve(t.Value)}
+	case *ast.ChanType:
+		return &TypeDesc{Kind: TypeKindChan, Elem: r.resolve(t.Value)}
+	case *ast.FuncType:
+		typeDesc := &TypeDesc{Kind: TypeKindFunc}
+		typeDesc.Params = r.resolveFields(t.Params)
+		typeDesc.Results = r.resolveFields(t.Results)
+		return typeDesc
+	case *ast.StructType:
+		return &TypeDesc{Kind: TypeKindStruct}
+	case *ast.InterfaceType:
+		return &TypeDesc{Kind: TypeKindInterface}
+	case *ast.ParenExpr:
+		return r.resolve(t.X)
+	case *ast.IndexExpr:
+		typeDesc := r.resolve(t.X)
+		if typeDesc != nil {
+			typeDesc.TypeArgs = []*TypeDesc{r.resolve(t.Index)}
+		}
+		return typeDesc
+	case *ast.IndexListExpr:
+		typeDesc := r.resolve(t.X)
+		if typeDesc != nil {
+			for _, index := range t.Indices {
+				typeDesc.TypeArgs = append(typeDesc.TypeArgs, r.resolve(index))
+			}
+		}
+		return typeDesc
+	default:
+		return nil
+	}
+}
+
+// resolveFields 解析参数或返回值列表的类型 a, b int 按参数个数重复
+func (r *typeResolver) resolveFields(fieldList *ast.FieldList) []*TypeDesc {
+	if fieldList == nil {
+		return nil
+	}
+	var result []*TypeDesc
+	for _, field := range fieldList.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			result = append(result, r.resolve(field.Type))
+		}
+	}
+	return result
+}
+
+// isValidType 类型及其元素、键、泛型实参中均不含无效类型
+func isValidType(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Basic:
+		return t.Kind() != types.Invalid
+	case *types.Named:
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if !isValidType(t.TypeArgs().At(i)) {
+				return false
+			}
+		}
+		return true
+	case *types.Pointer:
+		return isValidType(t.Elem())
+	case *types.Slice:
+		return isValidType(t.Elem())
+	case *types.Array:
+		return isValidType(t.Elem())
+	case *types.Map:
+		return isValidType(t.Key()) && isValidType(t.Elem())
+	case *types.Chan:
+		return isValidType(t.Elem())
+	case *types.Signature:
+		return isValidTuple(t.Params()) && isValidTuple(t.Results())
+	default:
+		return true
+	}
+}
+
+func isValidTuple(tuple *types.Tuple) bool {
+	for i := 0; i < tuple.Len(); i++ {
+		if !isValidType(tuple.At(i).Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeDescFromTypes 根据类型检查结果构建类型信息
+func typeDescFromTypes(t types.Type) *TypeDesc {
+	switch t := t.(type) {
+	case *types.Basic:
+		return &TypeDesc{Kind: TypeKindBasic, TypeName: t.Name()}
+	case *types.Alias:
+		// Alias.TypeArgs 自go1.23起提供 泛型别名仅在更高版本中出现
+		var typeArgs *types.TypeList
+		if generic, ok := types.Type(t).(interface{ TypeArgs() *types.TypeList }); ok {
+			typeArgs = generic.TypeArgs()
+		}
+		return namedTypeDesc(t.Obj(), typeArgs)
+	case *types.Named:
+		return namedTypeDesc(t.Obj(), t.TypeArgs())
+	case *types.TypeParam:
+		return &TypeDesc{Kind: TypeKindTypeParam, TypeName: t.Obj().Name()}
+	case *types.Pointer:
+		return &TypeDesc{Kind: TypeKindPointer, Elem: typeDescFromTypes(t.Elem())}
+	case *types.Slice:
+		return &TypeDesc{Kind: TypeKindSlice, Elem: typeDescFromTypes(t.Elem())}
+	case *types.Array:
+		return &TypeDesc{Kind: TypeKindArray, Elem: typeDescFromTypes(t.Elem())}
+	case *types.Map:
+		return &TypeDesc{Kind: TypeKindMap, Key: typeDescFromTypes(t.Key()), Elem: typeDescFromTypes(t.Elem())}
+	case *types.Chan:
+		return &TypeDesc{Kind: TypeKindChan, Elem: typeDescFromTypes(t.Elem())}
+	case *types.Signature:
+		typeDesc := &TypeDesc{Kind: TypeKindFunc}
+		typeDesc.Params = typeDescsFromTuple(t.Params())
+		typeDesc.Results = typeDescsFromTuple(t.Results())
+		return typeDesc
+	case *types.Struct:
+		return &TypeDesc{Kind: TypeKindStruct}
+	case *types.Interface:
+		return &TypeDesc{Kind: TypeKindInterface}
+	default:
+		return nil
+	}
+}
+
+func typeDescsFromTuple(tuple *types.Tuple) []*TypeDesc {
+	var result []*TypeDesc
+	for i := 0; i < tuple.Len(); i++ {
+		result = append(result, typeDescFromTypes(tuple.At(i).Type()))
+	}
+	return result
+}
+
+// addTypeImports 收集类型及其元素、键、泛型实参、函数参数及返回值中具名类型的导入
+// 按导入路径在文件的导入中查找 点导入的类型也能找到 结果按导入名存放
+func addTypeImports(imports map[string]*ImportDesc, fileImports map[string]*ImportDesc, typeDesc *TypeDesc) {
+	if typeDesc == nil {
+		return
+	}
+	if typeDesc.ImportPath != "" {
+		for name, imp := range fileImports {
+			if imp.Path == typeDesc.ImportPath {
+				imports[name] = imp
+			}
+		}
+	}
+	addTypeImports(imports, fileImports, typeDesc.Elem)
+	addTypeImports(imports, fileImports, typeDesc.Key)
+	for _, list := range [][]*TypeDesc{typeDesc.TypeArgs, typeDesc.Params, typeDesc.Results} {
+		for _, t := range list {
+			addTypeImports(imports, fileImports, t)
+		}
+	}
+}
+
+// addFieldImports 收集字段类型用到的导入
+// 除按类型收集外 仍按字段的包名查找 类型为别名且解析为其他包的类型时保留别名所在包的导入
+func addFieldImports(imports map[string]*ImportDesc, fileImports map[string]*ImportDesc, field *Field) {
+	if imp, ok := fileImports[field.PackageName]; ok {
+		imports[field.PackageName] = imp
+	}
+	addTypeImports(imports, fileImports, field.Type)
+}
+
+func namedTypeDesc(obj *types.TypeName, typeArgs *types.TypeList) *TypeDesc {
+	typeDesc := &TypeDesc{Kind: TypeKindNamed, TypeName: obj.Name()}
+	if pkg := obj.Pkg(); pkg != nil {
+		typeDesc.ImportPath = pkg.Path()
+		typeDesc.PackageName = pkg.Name()
+	}
+	for i := 0; i < typeArgs.Len(); i++ {
+		typeDesc.TypeArgs = append(typeDesc.TypeArgs, typeDescFromTypes(typeArgs.At(i)))
+	}
+	return typeDesc
+}
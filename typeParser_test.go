@@ -0,0 +1,126 @@
+package go_annotation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeResolver(t *testing.T) {
+	dataPath := "github.com/celt237/go-annotation/test/data"
+	localPath := "github.com/celt237/go-annotation/test/data/types"
+	named := func(importPath, packageName, typeName string) *TypeDesc {
+		return &TypeDesc{Kind: TypeKindNamed, ImportPath: importPath, PackageName: packageName, TypeName: typeName}
+	}
+	tests := []struct {
+		name   string
+		method string
+		param  int
+		result bool
+		want   *TypeDesc
+	}{
+		{
+			name:   "导入别名",
+			method: "Method1",
+			param:  0,
+			want:   named("context", "context", "Context"),
+		},
+		{
+			name:   "导入别名指针",
+			method: "Method1",
+			param:  1,
+			want:   &TypeDesc{Kind: TypeKindPointer, Elem: named(dataPath, "data", "A1")},
+		},
+		{
+			name:   "点导入",
+			method: "Method1",
+			param:  2,
+			want:   named(dataPath, "data", "A2"),
+		},
+		{
+			name:   "同包类型",
+			method: "Method1",
+			param:  3,
+			want:   &TypeDesc{Kind: TypeKindPointer, Elem: named(localPath, "types", "Local")},
+		},
+		{
+			name:   "内置error",
+			method: "Method1",
+			result: true,
+			want:   named("", "", "error"),
+		},
+		{
+			name:   "map",
+			method: "Method2",
+			param:  0,
+			want: &TypeDesc{
+				Kind: TypeKindMap,
+				Key:  &TypeDesc{Kind: TypeKindBasic, TypeName: "string"},
+				Elem: &TypeDesc{Kind: TypeKindSlice, Elem: &TypeDesc{Kind: TypeKindPointer, Elem: named(dataPath, "data", "A3")}},
+			},
+		},
+		{
+			name:   "chan",
+			method: "Method2",
+			param:  1,
+			want:   &TypeDesc{Kind: TypeKindChan, Elem: &TypeDesc{Kind: TypeKindBasic, TypeName: "int"}},
+		},
+		{
+			name:   "func",
+			method: "Method2",
+			param:  2,
+			want:   &TypeDesc{Kind: TypeKindFunc, Results: []*TypeDesc{named("", "", "error")}},
+		},
+		{
+			name:   "func参数",
+			method: "Method3",
+			param:  0,
+			want: &TypeDesc{
+				Kind:    TypeKindFunc,
+				Params:  []*TypeDesc{named("io", "io", "Reader")},
+				Results: []*TypeDesc{named("", "", "error")},
+			},
+		},
+		{
+			name:   "数组",
+			method: "Method2",
+			result: true,
+			want:   &TypeDesc{Kind: TypeKindArray, Elem: named(localPath, "types", "Local")},
+		},
+	}
+
+	fileDesc, err := GetFileDesc("test/data/types/types.go", AnnotationModeArray)
+	if err != nil {
+		t.Fatalf("GetFileDesc() error = %v", err)
+	}
+	pkgs, err := GetPackagesDescList(AnnotationModeArray, "./test/data/types")
+	if err != nil {
+		t.Fatalf("GetPackagesDescList() error = %v", err)
+	}
+	sources := map[string]*FileDesc{"file": fileDesc, "package": pkgs[0].Files[0]}
+	for source, desc := range sources {
+		methods := make(map[string]*MethodDesc)
+		for _, method := range desc.Interfaces[0].Methods {
+			methods[method.Name] = method
+		}
+		for _, tt := range tests {
+			t.Run(source+"/"+tt.name, func(t *testing.T) {
+				fields := methods[tt.method].Params
+				if tt.result {
+					fields = methods[tt.method].Results
+				}
+				if got := fields[tt.param].Type; !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Type = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+		// 点导入及仅用于复合类型的导入均需收集
+		t.Run(source+"/导入", func(t *testing.T) {
+			imports := desc.Interfaces[0].Imports
+			for _, name := range []string{"bytes", "ctx", "io", ".", "d"} {
+				if _, ok := imports[name]; !ok {
+					t.Errorf("Imports missing %q, got %v", name, imports)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package go_annotation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshal 将注解的第一组属性绑定到结构体
+// v 必须为结构体指针 字段通过 annotation 标签指定属性名及选项 如
+//
+//	type Route struct {
+//		Path    string        `annotation:"path,required"`
+//		Method  string        `annotation:"method,default=GET"`
+//		Timeout time.Duration `annotation:"timeout,default=1s"`
+//	}
+//
+// 未设置标签时按字段名匹配属性(不区分大小写) 标签为 - 的字段忽略 default 须为最后一个选项
+// 数组模式的属性名为其位置 如 annotation:"0"
+func (a *Annotation) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("annotation %s: unmarshal target must be a non-nil pointer to struct, got %T", a.Name, v)
+	}
+	return a.unmarshalAt(0, rv.Elem())
+}
+
+// UnmarshalAll 将注解的每组属性分别绑定到结构体 v 必须为结构体切片的指针 如 *[]Route
+func (a *Annotation) UnmarshalAll(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice || indirectType(rv.Elem().Type().Elem()).Kind() != reflect.Struct {
+		return fmt.Errorf("annotation %s: unmarshal target must be a pointer to slice of struct, got %T", a.Name, v)
+	}
+	count := len(a.Attributes)
+	if count == 0 {
+		count = 1
+	}
+	slice := reflect.MakeSlice(rv.Elem().Type(), count, count)
+	for i := 0; i < count; i++ {
+		if err := a.unmarshalAt(i, indirect(slice.Index(i))); err != nil {
+			return err
+		}
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// attributeValues 获取第index组属性值 没有类型信息的属性按字面量解析
+func (a *Annotation) attributeValues(index int) map[string]*AnnotationValue {
+	values := make(map[string]*AnnotationValue)
+	if index >= len(a.Attributes) {
+		return values
+	}
+	for name := range a.Attributes[index] {
+		values[name] = attributeValue(a, index, name)
+	}
+	return values
+}
+
+func (a *Annotation) unmarshalAt(index int, rv reflect.Value) error {
+	values := a.attributeValues(index)
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag, ok := parseAnnotationTag(field)
+		if !ok {
+			continue
+		}
+		value, found := values[tag.name]
+		if !found && field.Tag.Get("annotation") == "" {
+			for name, v := range values {
+				if strings.EqualFold(name, tag.name) {
+					value, found = v, true
+					break
+				}
+			}
+		}
+		if !found {
+			if tag.required {
+				return fmt.Errorf("annotation %s: missing required attribute %s", a.Name, tag.name)
+			}
+			if !tag.hasDefault {
+				continue
+			}
+			var err error
+			if value, err = parseAnnotationValue(tag.defaultValue); err != nil {
+				value = &AnnotationValue{Kind: AnnotationValueString, Raw: tag.defaultValue, String: tag.defaultValue}
+			}
+		}
+		if err := setValue(rv.Field(i), value); err != nil {
+			return fmt.Errorf("annotation %s: attribute %s: %s", a.Name, tag.name, err)
+		}
+	}
+	return nil
+}
+
+type annotationTag struct {
+	name         string
+	required     bool
+	hasDefault   bool
+	defaultValue string
+}
+
+// parseAnnotationTag 解析字段标签 返回false表示忽略该字段
+func parseAnnotationTag(field reflect.StructField) (annotationTag, bool) {
+	tag := annotationTag{name: field.Name}
+	text := field.Tag.Get("annotation")
+	if text == "-" {
+		return tag, false
+	}
+	name, options, _ := strings.Cut(text, ",")
+	if name != "" {
+		tag.name = name
+	}
+	for options != "" {
+		var option string
+		if strings.HasPrefix(options, "default=") {
+			tag.hasDefault, tag.defaultValue = true, strings.TrimPrefix(options, "default=")
+			break
+		}
+		option, options, _ = strings.Cut(options, ",")
+		if option == "required" {
+			tag.required = true
+		}
+	}
+	return tag, true
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// indirect 为空指针分配内存 返回指针指向的值
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// setValue 将属性值转换为字段类型并赋值
+func setValue(rv reflect.Value, value *AnnotationValue) error {
+	rv = indirect(rv)
+	if rv.Type() == durationType {
+		switch value.Kind {
+		case AnnotationValueDuration:
+			rv.SetInt(int64(value.Duration))
+			return nil
+		case AnnotationValueString, AnnotationValueIdent:
+			d, err := time.ParseDuration(value.String)
+			if err != nil {
+				return fmt.Errorf("cannot convert %q to time.Duration", value.String)
+			}
+			rv.SetInt(int64(d))
+			return nil
+		}
+		return cannotConvert(value, rv.Type())
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(value.text())
+	case reflect.Bool:
+		switch value.Kind {
+		case AnnotationValueBool:
+			rv.SetBool(value.Bool)
+		case AnnotationValueString:
+			b, err := strconv.ParseBool(value.String)
+			if err != nil {
+				return cannotConvert(value, rv.Type())
+			}
+			rv.SetBool(b)
+		default:
+			return cannotConvert(value, rv.Type())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := valueInt(value)
+		if err != nil || rv.OverflowInt(i) {
+			return cannotConvert(value, rv.Type())
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := valueInt(value)
+		if err != nil || i < 0 || rv.OverflowUint(uint64(i)) {
+			return cannotConvert(value, rv.Type())
+		}
+		rv.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch value.Kind {
+		case AnnotationValueFloat:
+			f = value.Float
+		case AnnotationValueInt:
+			f = float64(value.Int)
+		case AnnotationValueString:
+			var err error
+			if f, err = strconv.ParseFloat(value.String, 64); err != nil {
+				return cannotConvert(value, rv.Type())
+			}
+		default:
+			return cannotConvert(value, rv.Type())
+		}
+		rv.SetFloat(f)
+	case reflect.Slice:
+		items := value.List
+		if value.Kind != AnnotationValueList {
+			// 单个值视为只有一个元素的列表
+			items = []*AnnotationValue{value}
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), item); err != nil {
+				return fmt.Errorf("[%d]: %s", i, err)
+			}
+		}
+		rv.Set(slice)
+	case reflect.Struct:
+		if value.Kind != AnnotationValueAnnotation {
+			return cannotConvert(value, rv.Type())
+		}
+		return value.Annotation.unmarshalAt(0, rv)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return cannotConvert(value, rv.Type())
+		}
+		rv.Set(reflect.ValueOf(value.Interface()))
+	default:
+		return fmt.Errorf("unsupported type %s", rv.Type())
+	}
+	return nil
+}
+
+func valueInt(value *AnnotationValue) (int64, error) {
+	switch value.Kind {
+	case AnnotationValueInt:
+		return value.Int, nil
+	case AnnotationValueString:
+		return strconv.ParseInt(value.String, 0, 64)
+	default:
+		return 0, fmt.Errorf("not an integer")
+	}
+}
+
+func cannotConvert(value *AnnotationValue, t reflect.Type) error {
+	return fmt.Errorf("cannot convert %s %s to %s", value.Kind, value.Raw, t)
+}
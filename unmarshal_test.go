@@ -0,0 +1,120 @@
+package go_annotation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testHeader struct {
+	Name     string `annotation:"name,required"`
+	Required bool   `annotation:"required"`
+}
+
+type testRoute struct {
+	Path     string        `annotation:"path,required"`
+	Method   string        `annotation:"method,default=GET"`
+	Retry    int           `annotation:"retry"`
+	Ratio    float64       `annotation:"ratio"`
+	Enabled  *bool         `annotation:"enabled"`
+	Timeout  time.Duration `annotation:"timeout,default=1s"`
+	Tags     []string      `annotation:"tags"`
+	Codes    []uint8       `annotation:"codes"`
+	Headers  []testHeader  `annotation:"headers"`
+	Auth     *testHeader   `annotation:"auth"`
+	Extra    interface{}   `annotation:"extra"`
+	Desc     string
+	Ignored  string `annotation:"-"`
+	internal string
+}
+
+func TestAnnotationUnmarshal(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name    string
+		parser  AnnotationParser
+		comment string
+		want    testRoute
+		wantErr string
+	}{
+		{
+			name:    "map模式",
+			parser:  &MapAnnotationParser{},
+			comment: `route(path="/a", retry=3, ratio=1, enabled=true, timeout=5s, tags=["a", "b"], codes=[200], headers=[@h(name="x", required=true)], auth=@h(name="y"), extra=[1], Desc="d", Ignored="i")`,
+			want: testRoute{Path: "/a", Method: "GET", Retry: 3, Ratio: 1, Enabled: &enabled, Timeout: 5 * time.Second, Tags: []string{"a", "b"}, Codes: []uint8{200},
+				Headers: []testHeader{{Name: "x", Required: true}}, Auth: &testHeader{Name: "y"}, Extra: []interface{}{int64(1)}, Desc: "d"},
+		},
+		{
+			name:    "字符串转换",
+			parser:  &MapAnnotationParser{},
+			comment: `route(path="/a", retry="3", enabled="true", timeout="2m", tags="a")`,
+			want:    testRoute{Path: "/a", Method: "GET", Retry: 3, Enabled: &enabled, Timeout: 2 * time.Minute, Tags: []string{"a"}},
+		},
+		{
+			name:    "缺少必填属性",
+			parser:  &MapAnnotationParser{},
+			comment: `route(method="POST")`,
+			wantErr: "annotation route: missing required attribute path",
+		},
+		{
+			name:    "类型错误",
+			parser:  &MapAnnotationParser{},
+			comment: `route(path="/a", retry="x")`,
+			wantErr: `annotation route: attribute retry: cannot convert string "x" to int`,
+		},
+		{
+			name:    "溢出",
+			parser:  &MapAnnotationParser{},
+			comment: `route(path="/a", codes=[256])`,
+			wantErr: "annotation route: attribute codes: [0]: cannot convert int 256 to uint8",
+		},
+		{
+			name:    "嵌套注解错误",
+			parser:  &MapAnnotationParser{},
+			comment: `route(path="/a", auth=@h(required=true))`,
+			wantErr: "annotation route: attribute auth: annotation h: missing required attribute name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testRoute
+			for _, annotation := range tt.parser.Parse([]string{tt.comment}) {
+				err := annotation.Unmarshal(&got)
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Fatalf("Unmarshal() error = %v, want %s", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Unmarshal() error = %v", err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotationUnmarshalArrayMode(t *testing.T) {
+	type route struct {
+		Method string `annotation:"0,required"`
+		Path   string `annotation:"1"`
+		Retry  int    `annotation:"2,default=1"`
+	}
+	annotations := (&ArrayAnnotationParser{}).Parse([]string{"route GET /a", "route POST /b 3"})
+	var routes []route
+	if err := annotations["route"].UnmarshalAll(&routes); err != nil {
+		t.Fatal(err)
+	}
+	want := []route{{"GET", "/a", 1}, {"POST", "/b", 3}}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("UnmarshalAll() = %+v, want %+v", routes, want)
+	}
+	var r route
+	if err := annotations["route"].Unmarshal(r); err == nil || !strings.Contains(err.Error(), "non-nil pointer to struct") {
+		t.Errorf("Unmarshal() error = %v, want invalid target error", err)
+	}
+}
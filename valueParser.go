@@ -0,0 +1,168 @@
+package go_annotation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+)
+
+type ValueParser struct {
+	genDecl     *ast.GenDecl
+	fileImports map[string]*ImportDesc
+	options     *Options
+	resolver    *typeResolver
+	diagnostics *diagnosticCollector
+	info        *types.Info // 类型检查信息 用于计算常量值及推导省略的类型 为空时使用源码中的表达式
+}
+
+// NewValueParser 创建常量组、变量组解析器
+// genDecl: const 或 var 声明
+func NewValueParser(genDecl *ast.GenDecl,
+	fileImports map[string]*ImportDesc,
+	options *Options) *ValueParser {
+	return &ValueParser{
+		genDecl:     genDecl,
+		fileImports: fileImports,
+		options:     options.withDefaults(),
+		resolver:    &typeResolver{imports: fileImports},
+		diagnostics: newDiagnosticCollector(nil, ""),
+	}
+}
+
+// Parse 解析常量组或变量组 组及其中的值均无注解时返回nil
+func (s *ValueParser) Parse() (*ValueGroupDesc, error) {
+	if s.options.err != nil {
+		return nil, s.options.err
+	}
+	values, err := s.parseValues()
+	if err != nil {
+		return nil, err
+	}
+	group := &ValueGroupDesc{
+		IsConst:     s.genDecl.Tok == token.CONST,
+		Comments:    make([]string, 0),
+		Annotations: make(map[string]*Annotation),
+		Occurrences: make([]*AnnotationOccurrence, 0),
+		Values:      values,
+	}
+	// 分组的声明 注释属于整个组 未分组的声明如 const A = 1 注释属于其中的值 在parseValues中处理
+	if s.genDecl.Lparen.IsValid() && len(values) > 0 {
+		target := s.genDecl.Tok.String() + " " + values[0].Name
+		group.Comments, group.Annotations, group.Occurrences = parseAnnotations(target, s.options, s.diagnostics, s.genDecl.Doc)
+		group.Description = parseDescription(values[0].Name, s.genDecl.Doc)
+	}
+	if len(group.Comments) > 0 {
+		return group, nil
+	}
+	for _, value := range values {
+		if len(value.Comments) > 0 {
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+// hasValueAnnotations 常量组、变量组或其中的值是否带有注解
+func hasValueAnnotations(genDecl *ast.GenDecl, options *Options) bool {
+	if options.hasAnnotations(genDecl.Doc) {
+		return true
+	}
+	for _, spec := range genDecl.Specs {
+		if valueSpec, ok := spec.(*ast.ValueSpec); ok && (options.hasAnnotations(valueSpec.Doc) || options.hasAnnotations(valueSpec.Comment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValues 按声明顺序解析组中的所有值 a, b = 1, 2 会被拆分为两个值 空白标识符 _ 被忽略
+// 常量组中省略类型及表达式的行沿用上一行的类型及表达式
+func (s *ValueParser) parseValues() ([]*ValueDesc, error) {
+	if s.genDecl.Tok != token.CONST && s.genDecl.Tok != token.VAR {
+		return nil, fmt.Errorf("%s is not a const or var declaration", s.genDecl.Tok)
+	}
+	isConst := s.genDecl.Tok == token.CONST
+	values := make([]*ValueDesc, 0)
+	var typeExpr ast.Expr
+	var exprs []ast.Expr
+	for i, spec := range s.genDecl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if !isConst || valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+			typeExpr, exprs = valueSpec.Type, valueSpec.Values
+		}
+		doc := valueSpec.Doc
+		if doc == nil && !s.genDecl.Lparen.IsValid() {
+			doc = s.genDecl.Doc
+		}
+		for j, name := range valueSpec.Names {
+			// 空白标识符仅占位 如跳过iota的某个值
+			if name.Name == "_" {
+				continue
+			}
+			value := &ValueDesc{Name: name.Name}
+			if isConst {
+				value.Iota = i
+			}
+			if typeExpr != nil {
+				value.DataType = exprToString(typeExpr)
+			}
+			if j < len(exprs) {
+				value.Value = exprToString(exprs[j])
+			}
+			s.resolveValue(name, value)
+			value.Comments, value.Annotations, value.Occurrences = parseAnnotations(name.Name, s.options, s.diagnostics, doc, valueSpec.Comment)
+			value.Description = parseDescription(name.Name, doc)
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// resolveValue 根据类型检查信息填充常量值及类型
+func (s *ValueParser) resolveValue(name *ast.Ident, value *ValueDesc) {
+	if s.info == nil {
+		return
+	}
+	obj := s.info.Defs[name]
+	if obj == nil {
+		return
+	}
+	if c, ok := obj.(*types.Const); ok && c.Val().Kind() != constant.Unknown {
+		value.Value = c.Val().ExactString()
+	}
+	if basic, ok := obj.Type().(*types.Basic); ok && basic.Info()&types.IsUntyped != 0 {
+		return
+	}
+	if obj.Type() != nil && obj.Type() != types.Typ[types.Invalid] {
+		value.DataType = types.TypeString(obj.Type(), func(pkg *types.Package) string {
+			if pkg == obj.Pkg() {
+				return ""
+			}
+			return pkg.Name()
+		})
+	}
+}
+
+// checkPackage 对同包文件做类型检查 用于未按包加载时解析类型及计算常量值
+// 导入的包无法加载时相关的类型无效 不影响其余部分
+func checkPackage(fset *token.FileSet, files []*ast.File, packagePath string, importer types.Importer) *types.Info {
+	info := newTypesInfo()
+	config := &types.Config{
+		Importer:    importer,
+		FakeImportC: true,
+		Error:       func(err error) {},
+	}
+	_, _ = config.Check(packagePath, fset, files, info)
+	return info
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}